@@ -2,12 +2,14 @@ package common
 
 const RESULT_STATUS_SUCCESS = "SUCCESS"
 const RESULT_STATUS_FAIL = "FAIL"
+const RESULT_STATUS_SKIPPED = "SKIPPED"
 
 const EMPTY_STR = "-"
 
 const OK = "OK"
 const ALL_NAMESPACE = "all"
 const LIST_RELEASES = "LIST_RELEASES"
+const LIST_OUTDATED_RELEASES = "LIST_OUTDATED_RELEASES"
 const NAMESPACE_ALL_NOT_ALLOWED = "NAMESPACE_ALL_NOT_ALLOWED"
 
 // VAULT
@@ -19,6 +21,7 @@ const REPO_NAME_URL_REQUIRED = "REPO_NAME_URL_REQUIRED"
 const REPO_NAME_CONTAINS_SC = "REPO_NAME_CONTAINS_SC"
 const REPO_NAME_ALREADY_EXISTS = "REPO_NAME_ALREADY_EXISTS"
 const REPO_NAME_PATTERN_NOT_ALLOWED = "REPO_NAME_PATTERN_NOT_ALLOWED"
+const REPO_NAME_RESERVED = "REPO_NAME_RESERVED"
 const REPO_USERNAME_PASSWD_REQUIRED = "REPO_USERNAME_PASSWD_REQUIRED"
 const REPO_SAME_CONF_ALREADY_EXISTS = "REPO_SAME_CONF_ALREADY_EXISTS"
 const REPO_CANNOT_BE_REACHED = "REPO_CANNOT_BE_REACHED"
@@ -31,6 +34,15 @@ const REPO_CA_INVALID = "REPO_CA_INVALID"
 const REPO_CA_FAILED_SAVE = "REPO_CA_FAILED_SAVE"
 const REPO_CA_ALREADY_EXISTS = "REPO_CA_ALREADY_EXISTS"
 const REPO_CONNECT_TIMEOUT = "REPO_CONNECT_TIMEOUT"
+const REPO_CA_FAILED_READ = "REPO_CA_FAILED_READ"
+const REPO_URL_INVALID = "REPO_URL_INVALID"
+const REPO_URL_HTTPS_REQUIRED = "REPO_URL_HTTPS_REQUIRED"
+const REPO_URL_HOST_NOT_ALLOWED = "REPO_URL_HOST_NOT_ALLOWED"
+const REPO_URL_PRIVATE_ADDRESS = "REPO_URL_PRIVATE_ADDRESS"
+const REPO_INDEX_TOO_LARGE = "REPO_INDEX_TOO_LARGE"
+const REPO_IMPORT_MODE_INVALID = "REPO_IMPORT_MODE_INVALID"
+const REPO_IMPORT_FILE_INVALID = "REPO_IMPORT_FILE_INVALID"
+const REPO_EXPORT_CREDENTIALS_NOT_ALLOWED = "REPO_EXPORT_CREDENTIALS_NOT_ALLOWED"
 const INSTALLATION_FAILED = "INSTALLATION_FAILED"
 
 const CHART_INFO_INVALID = "CHART_INFO_INVALID"
@@ -38,15 +50,54 @@ const CHART_VERSION_INVALID = "CHART_VERSION_INVALID"
 const REVISION_NUMBER_INVALID = "REVISION_NUMBER_INVALID"
 
 const CHART_NOT_FOUND = "CHART_NOT_FOUND"
+const CHART_VERIFICATION_FAILED = "CHART_VERIFICATION_FAILED"
+const CHART_FILE_NOT_FOUND = "CHART_FILE_NOT_FOUND"
+const CHART_SCHEMA_INVALID = "CHART_SCHEMA_INVALID"
+const CHART_VALUES_INVALID = "CHART_VALUES_INVALID"
+const KUBE_VERSION_INVALID = "KUBE_VERSION_INVALID"
+const CHART_PACKAGE_REQUIRED = "CHART_PACKAGE_REQUIRED"
+const DEPENDENCY_REPO_NOT_FOUND = "DEPENDENCY_REPO_NOT_FOUND"
+const DEPENDENCY_UPDATE_FAILED = "DEPENDENCY_UPDATE_FAILED"
+const DEPENDENCY_CYCLE = "DEPENDENCY_CYCLE"
+const COMPARE_VERSIONS_REQUIRED = "COMPARE_VERSIONS_REQUIRED"
+const CHART_PACKAGE_INVALID = "CHART_PACKAGE_INVALID"
+const CHART_PROVENANCE_INVALID = "CHART_PROVENANCE_INVALID"
+const CHART_VERSION_ALREADY_EXISTS = "CHART_VERSION_ALREADY_EXISTS"
+const CHART_VERSION_NOT_FOUND = "CHART_VERSION_NOT_FOUND"
+const HOSTED_REPO_DISABLED = "HOSTED_REPO_DISABLED"
+const HOSTED_REPO_NOT_REMOVABLE = "HOSTED_REPO_NOT_REMOVABLE"
+const CHART_FILTER_INVALID = "CHART_FILTER_INVALID"
+const CHART_SORT_INVALID = "CHART_SORT_INVALID"
+
+// KEYRING
+const KEYRING_NAME_INVALID = "KEYRING_NAME_INVALID"
+const KEYRING_INVALID = "KEYRING_INVALID"
+const KEYRING_ALREADY_EXISTS = "KEYRING_ALREADY_EXISTS"
+const KEYRING_NOT_FOUND = "KEYRING_NOT_FOUND"
+const KEYRING_IN_USE = "KEYRING_IN_USE"
+const KEYRING_FAILED_SAVE = "KEYRING_FAILED_SAVE"
+const VERIFY_MODE_INVALID = "VERIFY_MODE_INVALID"
+const VERIFICATION_FAILED_LOADING_FILE = "VERIFICATION_FAILED_LOADING_FILE"
 const RELEASE_NOT_FOUND = "RELEASE_NOT_FOUND"
 const RELEASE_ALREADY_EXISTS = "RELEASE_ALREADY_EXISTS"
+const RELEASE_FILTER_INVALID = "RELEASE_FILTER_INVALID"
+const RELEASE_OPTION_INVALID = "RELEASE_OPTION_INVALID"
+const RELEASE_TEST_FAILED = "RELEASE_TEST_FAILED"
+const LOG_OPTION_INVALID = "LOG_OPTION_INVALID"
+const CLUSTER_REQUEST_TIMEOUT = "CLUSTER_REQUEST_TIMEOUT"
 
 const LIMIT_VAL_INVALID = "LIMIT_VAL_INVALID"
 const OFFSET_VAL_INVALID = "OFFSET_VAL_INVALID"
 const LIMIT_ILLEGAL_ARGUMENT = "LIMIT_ILLEGAL_ARGUMENT"
 const OFFSET_ILLEGAL_ARGUMENT = "OFFSET_ILLEGAL_ARGUMENT"
 const OFFSET_REQUIRES_LIMIT_ILLEGAL_ARGUMENT = "OFFSET_REQUIRES_LIMIT_ILLEGAL_ARGUMENT"
+const CONTINUE_REQUIRES_LIMIT_ILLEGAL_ARGUMENT = "CONTINUE_REQUIRES_LIMIT_ILLEGAL_ARGUMENT"
+const CONTINUE_VAL_INVALID = "CONTINUE_VAL_INVALID"
+const SORT_VAL_INVALID = "SORT_VAL_INVALID"
+const FILTER_VAL_INVALID = "FILTER_VAL_INVALID"
+const FIELDS_VAL_INVALID = "FIELDS_VAL_INVALID"
 const HUB_PACKAGE_LIMIT_ILLEGAL_ARGUMENT = "HUB_PACKAGE_LIMIT_ILLEGAL_ARGUMENT"
+const HUB_PACKAGE_TOO_MANY_RESULTS = "HUB_PACKAGE_TOO_MANY_RESULTS"
 
 const NOT_FOUND = "NOT_FOUND"
 const TOKEN_FAILED = "TOKEN_FAILED"
@@ -1,6 +1,7 @@
 package common
 
 import (
+	"context"
 	"fmt"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
@@ -9,12 +10,10 @@ import (
 	"go-api/config"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"os"
 	"strings"
-)
-
-var (
-	settings = cli.New()
+	"time"
 )
 
 type KubeInfo struct {
@@ -22,13 +21,15 @@ type KubeInfo struct {
 	AimNamespace string
 	AimApiServer string
 	AimToken     string
+	// Timeout bounds each request to the cluster, 0 does not time out
+	Timeout time.Duration
 }
 
 func InitKubeInfo(c *fiber.Ctx) (*KubeInfo, error) {
 	namespace := c.Params("namespace")
 	if strings.ToLower(namespace) == ALL_NAMESPACE {
-		if c.Route().Name != LIST_RELEASES {
-			// No other routes allow namespaces 'all' except list release
+		if c.Route().Name != LIST_RELEASES && c.Route().Name != LIST_OUTDATED_RELEASES {
+			// No other routes allow namespaces 'all' except the release lists
 			return nil, fmt.Errorf(NAMESPACE_ALL_NOT_ALLOWED)
 		}
 		namespace = ""
@@ -39,7 +40,7 @@ func InitKubeInfo(c *fiber.Ctx) (*KubeInfo, error) {
 		AimNamespace: namespace,
 	}
 
-	err := getKubeToken(c, kubeInfo)
+	err := getKubeToken(context.Background(), UserClaims(c), kubeInfo)
 	if err != nil {
 		return nil, err
 	}
@@ -47,21 +48,100 @@ func InitKubeInfo(c *fiber.Ctx) (*KubeInfo, error) {
 	return kubeInfo, nil
 }
 
+// InitClusterKubeInfo resolves the kube info of a cluster for the claims of a request,
+// it does not read the request so it can be used for other clusters than the one in the path.
+// The deadline of the context also bounds the requests to the cluster
+func InitClusterKubeInfo(ctx context.Context, claims jwt.MapClaims, clusterId string, namespace string) (*KubeInfo, error) {
+	kubeInfo := &KubeInfo{
+		AimCluster:   clusterId,
+		AimNamespace: namespace,
+	}
+
+	if err := getKubeToken(ctx, claims, kubeInfo); err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		kubeInfo.Timeout = time.Until(deadline)
+		if kubeInfo.Timeout <= 0 {
+			return nil, ctx.Err()
+		}
+	}
+
+	return kubeInfo, nil
+}
+
+// SystemClaims are the claims of the background jobs, they read the clusters with the cluster tokens of SUPER_ADMIN
+func SystemClaims() jwt.MapClaims {
+	return jwt.MapClaims{"userType": AUTH_SUPER_ADMIN, "userAuthId": ""}
+}
+
+func UserClaims(c *fiber.Ctx) jwt.MapClaims {
+	return c.Locals("user").(*jwt.Token).Claims.(jwt.MapClaims)
+}
+
+// AccessibleClusters returns the clusters of the rolesInfo of the claims, and all the vault clusters for SUPER_ADMIN
+func AccessibleClusters(claims jwt.MapClaims) ([]string, error) {
+	if claims["userType"].(string) == AUTH_SUPER_ADMIN {
+		keys, err := list(config.Env.VaultClusterPath)
+		if err != nil {
+			log.Errorf("AccessibleClusters :: list :: () %v", err)
+			return nil, fmt.Errorf(FAILED_TO_READ_CLUSTER_INFO)
+		}
+		clusters := make([]string, 0, len(keys))
+		for _, k := range keys {
+			if !strings.HasSuffix(k, "/") {
+				clusters = append(clusters, k)
+			}
+		}
+		return clusters, nil
+	}
+
+	rolesInfo, _ := claims["rolesInfo"].(map[string]interface{})
+	clusters := make([]string, 0, len(rolesInfo))
+	for clusterId := range rolesInfo {
+		clusters = append(clusters, clusterId)
+	}
+	return clusters, nil
+}
+
+// ClusterUserType returns the user type of the claims in a cluster, the type of its rolesInfo entry,
+// SUPER_ADMIN for all the clusters and empty for the clusters not in the rolesInfo
+func ClusterUserType(claims jwt.MapClaims, clusterId string) string {
+	if claims["userType"].(string) == AUTH_SUPER_ADMIN {
+		return AUTH_SUPER_ADMIN
+	}
+	rolesInfo, _ := claims["rolesInfo"].(map[string]interface{})
+	clusterInfo, _ := rolesInfo[clusterId].(map[string]interface{})
+	userType, _ := clusterInfo["userType"].(string)
+	return userType
+}
+
 func ActionConfigInit(c *fiber.Ctx) (*action.Configuration, error) {
 	kubeInfo, err := InitKubeInfo(c)
 	if err != nil {
 		return nil, err
 	}
 
+	return KubeActionConfigInit(kubeInfo)
+}
+
+// KubeActionConfigInit creates the action configuration of a cluster with its own settings,
+// so concurrent requests to different clusters do not share the api server and token
+func KubeActionConfigInit(kubeInfo *KubeInfo) (*action.Configuration, error) {
 	actionConfig := new(action.Configuration)
+	settings := cli.New()
 	settings.KubeAPIServer = kubeInfo.AimApiServer
 	settings.KubeToken = kubeInfo.AimToken
 	settings.KubeInsecureSkipTLSVerify = true
 	settings.SetNamespace(kubeInfo.AimNamespace)
+	if flags, ok := settings.RESTClientGetter().(*genericclioptions.ConfigFlags); ok && kubeInfo.Timeout > 0 {
+		timeout := kubeInfo.Timeout.String()
+		flags.Timeout = &timeout
+	}
 
 	log.Infof("SEND :: CLUSTER: %v, NAMESPACE: %v", kubeInfo.AimCluster, kubeInfo.AimNamespace)
 
-	err = actionConfig.Init(settings.RESTClientGetter(), kubeInfo.AimNamespace, os.Getenv("HELM_DRIVER"), glog.Infof)
+	err := actionConfig.Init(settings.RESTClientGetter(), kubeInfo.AimNamespace, os.Getenv("HELM_DRIVER"), glog.Infof)
 	if err != nil {
 		glog.Errorf("%+v", err)
 		return nil, err
@@ -70,8 +150,7 @@ func ActionConfigInit(c *fiber.Ctx) (*action.Configuration, error) {
 	return actionConfig, nil
 }
 
-func getKubeToken(c *fiber.Ctx, kubeInfo *KubeInfo) error {
-	claims := c.Locals("user").(*jwt.Token).Claims.(jwt.MapClaims)
+func getKubeToken(ctx context.Context, claims jwt.MapClaims, kubeInfo *KubeInfo) error {
 	userType := claims["userType"].(string) // SUPER_ADMIN, CLUSTER_ADMIN, USER
 	userAuthId := claims["userAuthId"].(string)
 
@@ -85,23 +164,23 @@ func getKubeToken(c *fiber.Ctx, kubeInfo *KubeInfo) error {
 		}
 
 		userType = clusterInfo.(map[string]interface{})["userType"].(string)
-		if err := getUserToken(userType, userAuthId, kubeInfo); err != nil {
+		if err := getUserToken(ctx, userType, userAuthId, kubeInfo); err != nil {
 			return err
 		}
 	}
 
 	//get cluster detailS
-	if err := getClusterDetails(userType, kubeInfo); err != nil {
+	if err := getClusterDetails(ctx, userType, kubeInfo); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func getClusterDetails(userType string, kubeInfo *KubeInfo) error {
+func getClusterDetails(ctx context.Context, userType string, kubeInfo *KubeInfo) error {
 	path := fmt.Sprintf("%v/%v", config.Env.VaultClusterPath, kubeInfo.AimCluster)
 
-	data, err := read(path)
+	data, err := read(ctx, path)
 	if err != nil {
 		log.Errorf("getClusterDetails :: read :: () %v", err)
 		return fmt.Errorf(FAILED_TO_READ_CLUSTER_INFO)
@@ -117,13 +196,17 @@ func getClusterDetails(userType string, kubeInfo *KubeInfo) error {
 	return nil
 }
 
-func getUserToken(userType string, userAuthId string, kubeInfo *KubeInfo) error {
+func getUserToken(ctx context.Context, userType string, userAuthId string, kubeInfo *KubeInfo) error {
 	path := fmt.Sprintf("%v/%v/%v", config.Env.VaultUserPath, userAuthId, kubeInfo.AimCluster)
 	if userType == AUTH_USER {
+		if kubeInfo.AimNamespace == "" {
+			// USER tokens are issued per namespace
+			return fmt.Errorf(NAMESPACE_ALL_NOT_ALLOWED)
+		}
 		path = fmt.Sprintf("%v/%v", path, kubeInfo.AimNamespace)
 	}
 
-	data, err := read(path)
+	data, err := read(ctx, path)
 	if err != nil {
 		log.Errorf("getUserToken :: read :: () %v", err)
 		return fmt.Errorf(FAILED_TO_READ_CLUSTER_INFO)
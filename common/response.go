@@ -23,17 +23,24 @@ type ListResultStatus struct {
 	Items          interface{} `json:"items"`
 }
 
+// PartialListResultStatus is a list merged from several sources, with the errors of the sources that failed
+type PartialListResultStatus struct {
+	ListResultStatus
+	Errors interface{} `json:"errors"`
+}
+
 type ListCount struct {
-	AllItemCount       int `json:"allItemCount"`
-	RemainingItemCount int `json:"remainingItemCount"`
+	AllItemCount       int    `json:"allItemCount"`
+	RemainingItemCount int    `json:"remainingItemCount"`
+	Continue           string `json:"continue,omitempty"`
 }
 
 func RespOK(c *fiber.Ctx, data interface{}) error {
 	resultStatus := ResultStatus{
 		ResultCode:     RESULT_STATUS_SUCCESS,
-		ResultMessage:  localize(c, "OK"),
+		ResultMessage:  Localize(c, "OK"),
 		HttpStatusCode: fiber.StatusOK,
-		DetailMessage:  localize(c, "OK"),
+		DetailMessage:  Localize(c, "OK"),
 		Items:          data,
 	}
 	return c.Status(fiber.StatusOK).JSON(resultStatus)
@@ -43,9 +50,9 @@ func RespOK(c *fiber.Ctx, data interface{}) error {
 func ListRespOK(c *fiber.Ctx, listCount ListCount, data interface{}) error {
 	listResultStatus := ListResultStatus{
 		ResultCode:     RESULT_STATUS_SUCCESS,
-		ResultMessage:  localize(c, "OK"),
+		ResultMessage:  Localize(c, "OK"),
 		HttpStatusCode: fiber.StatusOK,
-		DetailMessage:  localize(c, "OK"),
+		DetailMessage:  Localize(c, "OK"),
 		ItemMetaData:   listCount,
 		Items:          data,
 	}
@@ -53,30 +60,58 @@ func ListRespOK(c *fiber.Ctx, listCount ListCount, data interface{}) error {
 
 }
 
+func PartialListRespOK(c *fiber.Ctx, listCount ListCount, data interface{}, errs interface{}) error {
+	partialListResultStatus := PartialListResultStatus{
+		ListResultStatus: ListResultStatus{
+			ResultCode:     RESULT_STATUS_SUCCESS,
+			ResultMessage:  Localize(c, "OK"),
+			HttpStatusCode: fiber.StatusOK,
+			DetailMessage:  Localize(c, "OK"),
+			ItemMetaData:   listCount,
+			Items:          data,
+		},
+		Errors: errs,
+	}
+	return c.Status(fiber.StatusOK).JSON(partialListResultStatus)
+
+}
+
 func RespErr(c *fiber.Ctx, err error) error {
 	log.Errorf("[RespErr Reason]: %s", err.Error())
 	resultStatus := ResultStatus{
 		ResultCode:     RESULT_STATUS_FAIL,
-		ResultMessage:  localize(c, err.Error()),
+		ResultMessage:  Localize(c, err.Error()),
 		HttpStatusCode: fiber.StatusBadRequest,
-		DetailMessage:  localize(c, err.Error()),
+		DetailMessage:  Localize(c, err.Error()),
 		Items:          make([]string, 0),
 	}
 	return c.Status(200).JSON(resultStatus)
 }
 
+func RespErrItems(c *fiber.Ctx, err error, data interface{}) error {
+	log.Errorf("[RespErr Reason]: %s", err.Error())
+	resultStatus := ResultStatus{
+		ResultCode:     RESULT_STATUS_FAIL,
+		ResultMessage:  Localize(c, err.Error()),
+		HttpStatusCode: fiber.StatusBadRequest,
+		DetailMessage:  Localize(c, err.Error()),
+		Items:          data,
+	}
+	return c.Status(200).JSON(resultStatus)
+}
+
 func RespErrStatus(c *fiber.Ctx, statusCode int, err error) error {
 	log.Errorf("[RespErr Reason]: %s", err.Error())
 	resultStatus := ResultStatus{
 		ResultCode:     RESULT_STATUS_FAIL,
 		ResultMessage:  err.Error(),
 		HttpStatusCode: statusCode,
-		DetailMessage:  localize(c, err.Error()),
+		DetailMessage:  Localize(c, err.Error()),
 		Items:          make([]string, 0),
 	}
 	return c.Status(statusCode).JSON(resultStatus)
 }
-func localize(c *fiber.Ctx, msg string) string {
+func Localize(c *fiber.Ctx, msg string) string {
 	localize_msg, err := fiberi18n.Localize(c, msg)
 	if err != nil {
 		return msg
@@ -5,13 +5,13 @@ import (
 	"github.com/hashicorp/vault-client-go"
 	"github.com/hashicorp/vault-client-go/schema"
 	"go-api/config"
+	"strings"
 	"time"
 )
 
-func read(path string) (map[string]interface{}, error) {
-	ctx := context.Background()
+func read(ctx context.Context, path string) (map[string]interface{}, error) {
 	//get vault client
-	client, err := getVaultClient()
+	client, err := getVaultClient(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -25,8 +25,47 @@ func read(path string) (map[string]interface{}, error) {
 	return resp.Data["data"].(map[string]interface{}), nil
 }
 
-func getVaultClient() (*vault.Client, error) {
+// list returns the keys under a kv v2 path, sub paths keep their trailing slash
+func list(path string) ([]string, error) {
 	ctx := context.Background()
+	//get vault client
+	client, err := getVaultClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// kv v2 lists the keys from the metadata of the data path
+	resp, err := client.List(ctx, kvMetadataPath(path))
+	if err != nil {
+		return nil, err
+	}
+
+	keys, _ := resp.Data["keys"].([]interface{})
+	result := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if key, ok := k.(string); ok {
+			result = append(result, key)
+		}
+	}
+	return result, nil
+}
+
+// kvMetadataPath turns mount/data/path into mount/metadata/path
+func kvMetadataPath(path string) string {
+	mount, rest, ok := strings.Cut(path, "/")
+	if !ok {
+		return path
+	}
+	if rest == "data" {
+		return mount + "/metadata"
+	}
+	if sub, found := strings.CutPrefix(rest, "data/"); found {
+		return mount + "/metadata/" + sub
+	}
+	return path
+}
+
+func getVaultClient(ctx context.Context) (*vault.Client, error) {
 	// prepare a client with the given base address
 	client, err := vault.New(
 		vault.WithAddress(config.Env.VaultUrl),
@@ -5,6 +5,7 @@ import (
 	"github.com/spf13/viper"
 	"helm.sh/helm/v3/pkg/repo"
 	"os"
+	"time"
 )
 
 var Env *envConfigs
@@ -15,23 +16,34 @@ func InitEnvConfigs() {
 }
 
 type envConfigs struct {
-	ServerPort                string `mapstructure:"SERVER_PORT"`
-	JwtSecret                 string `mapstructure:"JWT_SECRET"`
-	HelmRepoConfig            string `mapstructure:"HELM_REPO_CONFIG"`
-	HelmRepoCache             string `mapstructure:"HELM_REPO_CACHE"`
-	HelmRepoCA                string `mapstructure:"HELM_REPO_CA"`
-	ArtifactHubUrl            string `mapstructure:"ARTIFACT_HUB_API_URL"`
-	ArtifactHubRepoSearch     string `mapstructure:"ARTIFACT_HUB_REPO_SEARCH"`
-	ArtifactHubPackageSearch  string `mapstructure:"ARTIFACT_HUB_PACKAGE_SEARCH"`
-	ArtifactHubPackageDetail  string `mapstructure:"ARTIFACT_HUB_PACKAGE_DETAIL"`
-	ArtifactHubPackageValues  string `mapstructure:"ARTIFACT_HUB_PACKAGE_VALUES"`
-	ArtifactHubPackageLogoUrl string `mapstructure:"ARTIFACT_HUB_PACKAGE_LOGO_URL"`
-	VaultUrl                  string `mapstructure:"VAULT_URL"`
-	VaultRoleName             string `mapstructure:"VAULT_ROLE_NAME"`
-	VaultRoleId               string `mapstructure:"VAULT_ROLE_ID"`
-	VaultSecretId             string `mapstructure:"VAULT_SECRET_ID"`
-	VaultClusterPath          string `mapstructure:"VAULT_CLUSTER_PATH"`
-	VaultUserPath             string `mapstructure:"VAULT_USER_PATH"`
+	ServerPort                string        `mapstructure:"SERVER_PORT"`
+	JwtSecret                 string        `mapstructure:"JWT_SECRET"`
+	HelmRepoConfig            string        `mapstructure:"HELM_REPO_CONFIG"`
+	HelmRepoCache             string        `mapstructure:"HELM_REPO_CACHE"`
+	HelmRepoCA                string        `mapstructure:"HELM_REPO_CA"`
+	HelmRepoKeyring           string        `mapstructure:"HELM_REPO_KEYRING"`
+	RepoAllowedHosts          []string      `mapstructure:"REPO_ALLOWED_HOSTS"`
+	RepoRequireHttps          bool          `mapstructure:"REPO_REQUIRE_HTTPS"`
+	RepoBlockedCIDRs          []string      `mapstructure:"REPO_BLOCKED_CIDRS"`
+	RepoMaxIndexSize          int64         `mapstructure:"REPO_MAX_INDEX_SIZE"`
+	HostedRepoDir             string        `mapstructure:"HOSTED_REPO_DIR"`
+	HostedRepoName            string        `mapstructure:"HOSTED_REPO_NAME"`
+	HostedRepoURL             string        `mapstructure:"HOSTED_REPO_URL"`
+	InventoryConcurrency      int           `mapstructure:"INVENTORY_CONCURRENCY"`
+	InventoryClusterTimeout   time.Duration `mapstructure:"INVENTORY_CLUSTER_TIMEOUT"`
+	DriftCheckInterval        time.Duration `mapstructure:"DRIFT_CHECK_INTERVAL"`
+	ArtifactHubUrl            string        `mapstructure:"ARTIFACT_HUB_API_URL"`
+	ArtifactHubRepoSearch     string        `mapstructure:"ARTIFACT_HUB_REPO_SEARCH"`
+	ArtifactHubPackageSearch  string        `mapstructure:"ARTIFACT_HUB_PACKAGE_SEARCH"`
+	ArtifactHubPackageDetail  string        `mapstructure:"ARTIFACT_HUB_PACKAGE_DETAIL"`
+	ArtifactHubPackageValues  string        `mapstructure:"ARTIFACT_HUB_PACKAGE_VALUES"`
+	ArtifactHubPackageLogoUrl string        `mapstructure:"ARTIFACT_HUB_PACKAGE_LOGO_URL"`
+	VaultUrl                  string        `mapstructure:"VAULT_URL"`
+	VaultRoleName             string        `mapstructure:"VAULT_ROLE_NAME"`
+	VaultRoleId               string        `mapstructure:"VAULT_ROLE_ID"`
+	VaultSecretId             string        `mapstructure:"VAULT_SECRET_ID"`
+	VaultClusterPath          string        `mapstructure:"VAULT_CLUSTER_PATH"`
+	VaultUserPath             string        `mapstructure:"VAULT_USER_PATH"`
 }
 
 func loadEnvVariables() (config *envConfigs) {
@@ -68,4 +80,16 @@ func makeRepoConfig() {
 	if err := os.MkdirAll(Env.HelmRepoCA, os.ModePerm); err != nil {
 		log.Errorf("[FAILED TO CREATE CERT DIR] PATH:: %v, ERR:: %v", Env.HelmRepoCA, err)
 	}
+
+	// Check repository keyring path exists
+	if err := os.MkdirAll(Env.HelmRepoKeyring, os.ModePerm); err != nil {
+		log.Errorf("[FAILED TO CREATE KEYRING DIR] PATH:: %v, ERR:: %v", Env.HelmRepoKeyring, err)
+	}
+
+	// Check hosted repository path exists
+	if Env.HostedRepoDir != "" {
+		if err := os.MkdirAll(Env.HostedRepoDir, os.ModePerm); err != nil {
+			log.Errorf("[FAILED TO CREATE HOSTED REPO DIR] PATH:: %v, ERR:: %v", Env.HostedRepoDir, err)
+		}
+	}
 }
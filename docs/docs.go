@@ -23,6 +23,21 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
+        "/api/charts": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Charts"
+                ],
+                "summary": "Search Charts",
+                "responses": {}
+            }
+        },
         "/api/charts/:charts/versions": {
             "get": {
                 "consumes": [
@@ -38,6 +53,36 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/charts/lint": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Charts"
+                ],
+                "summary": "Lint Uploaded Chart",
+                "responses": {}
+            }
+        },
+        "/api/clusters/:clusterId/namespaces/:namespace/outdated": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "List Outdated Releases",
+                "responses": {}
+            }
+        },
         "/api/clusters/:clusterId/namespaces/:namespace/releases": {
             "get": {
                 "consumes": [
@@ -107,6 +152,36 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/drift": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Check Release Drift",
+                "responses": {}
+            }
+        },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/events": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Get Release Events",
+                "responses": {}
+            }
+        },
         "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/histories": {
             "get": {
                 "consumes": [
@@ -122,6 +197,52 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/hooks": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Get Release Hooks",
+                "responses": {}
+            }
+        },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/images": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Get Release Images",
+                "responses": {}
+            }
+        },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/logs": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json",
+                    "text/event-stream"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Get Release Pod Logs",
+                "responses": {}
+            }
+        },
         "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/resources": {
             "get": {
                 "consumes": [
@@ -137,6 +258,21 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/tests": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "Run Release Tests",
+                "responses": {}
+            }
+        },
         "/api/clusters/:clusterId/namespaces/:namespace/releases/:release/versions/:revision": {
             "put": {
                 "consumes": [
@@ -152,6 +288,36 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/hosted/charts": {
+            "post": {
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Hosted Repository"
+                ],
+                "summary": "Upload Chart to Hosted Repository",
+                "responses": {}
+            }
+        },
+        "/api/hosted/charts/:charts/versions/:version": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Hosted Repository"
+                ],
+                "summary": "Delete Chart Version from Hosted Repository",
+                "responses": {}
+            }
+        },
         "/api/hub/packages": {
             "get": {
                 "consumes": [
@@ -212,6 +378,94 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/keyrings": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keyrings"
+                ],
+                "summary": "List Keyrings",
+                "responses": {}
+            },
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keyrings"
+                ],
+                "summary": "Add Keyring",
+                "responses": {}
+            }
+        },
+        "/api/keyrings/:keyring": {
+            "delete": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Keyrings"
+                ],
+                "summary": "Remove Keyring",
+                "responses": {}
+            }
+        },
+        "/api/releases": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "List Releases of All Clusters",
+                "responses": {}
+            }
+        },
+        "/api/releases/drift": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "List Last Release Drift Reports",
+                "responses": {}
+            }
+        },
+        "/api/releases/outdated": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Releases"
+                ],
+                "summary": "List Outdated Releases of All Clusters",
+                "responses": {}
+            }
+        },
         "/api/repositories": {
             "get": {
                 "consumes": [
@@ -283,6 +537,66 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/repositories/:repositories/charts/:charts/compare": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Compare Chart Versions",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/dependencies": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Chart Dependencies",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/files/*": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Chart File",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/images": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Chart Images",
+                "responses": {}
+            }
+        },
         "/api/repositories/:repositories/charts/:charts/info": {
             "get": {
                 "consumes": [
@@ -298,6 +612,109 @@ const docTemplate = `{
                 "responses": {}
             }
         },
+        "/api/repositories/:repositories/charts/:charts/lint": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Lint Repository Chart",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/package": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Download Chart Package",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/schema": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Chart Values Schema",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/template": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Template Chart",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/charts/:charts/validate": {
+            "post": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Validate Chart Values",
+                "responses": {}
+            }
+        },
+        "/api/repositories/:repositories/verification": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Repository Verification",
+                "responses": {}
+            },
+            "put": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Set Repository Verification",
+                "responses": {}
+            }
+        },
         "/api/repositories/cache/clear": {
             "delete": {
                 "consumes": [
@@ -312,6 +729,63 @@ const docTemplate = `{
                 "summary": "Clear Repo Cache",
                 "responses": {}
             }
+        },
+        "/api/repositories/cache/stats": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Get Search Index Cache Stats",
+                "responses": {}
+            }
+        },
+        "/api/repositories/export": {
+            "get": {
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/x-yaml"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Export Repository Catalog",
+                "responses": {}
+            }
+        },
+        "/api/repositories/import": {
+            "post": {
+                "consumes": [
+                    "application/x-yaml"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "Repository"
+                ],
+                "summary": "Import Repository Catalog",
+                "responses": {}
+            }
+        },
+        "/charts/:file": {
+            "get": {
+                "produces": [
+                    "application/octet-stream"
+                ],
+                "tags": [
+                    "Hosted Repository"
+                ],
+                "summary": "Serve Hosted Repository Files",
+                "responses": {}
+            }
         }
     }
 }`
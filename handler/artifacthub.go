@@ -9,10 +9,18 @@ import (
 	"go-api/config"
 	"io"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 )
 
+const (
+	// the largest page of the artifact hub package search
+	hubPackagePageLimit = 60
+	// the most packages read from artifact hub to sort or filter them
+	hubPackageMaxResults = 600
+)
+
 type artifactRepositoryElement struct {
 	Id                string `json:"repository_id"`
 	Name              string `json:"name"`
@@ -109,36 +117,73 @@ func SearchPackageHub(c *fiber.Ctx) error {
 		return common.RespErr(c, err)
 	}
 
-	if lse.Limit < 1 || lse.Limit > 60 {
+	if lse.Limit < 1 || lse.Limit > hubPackagePageLimit {
 		return common.RespErr(c, fmt.Errorf(common.HUB_PACKAGE_LIMIT_ILLEGAL_ARGUMENT))
 	}
 	repo := c.Query("repo")
 	query := c.Query("query")
 
-	params := fmt.Sprintf("&offset=%v&limit=%v&ts_query_web=%v", lse.Offset*lse.Limit, lse.Limit, query)
-	if len(repo) > 0 {
-		params += "&repo=" + repo
-	}
+	// artifact hub pages the packages itself, a sort, filter or searchName is applied to all the matching packages
+	// so the page and the counts match. The searchName also narrows the hub query
+	if len(lse.Sort) > 0 || len(lse.Filters) > 0 || lse.SearchName != "" {
+		query = strings.TrimSpace(query + " " + lse.SearchName)
+		packages := make([]interface{}, 0)
+		for offset := 0; ; offset += hubPackagePageLimit {
+			page, totalCount, err := searchHubPackages(query, repo, offset, hubPackagePageLimit)
+			if err != nil {
+				return common.RespErr(c, err)
+			}
+			if totalCount > hubPackageMaxResults {
+				return common.RespErr(c, fmt.Errorf(common.HUB_PACKAGE_TOO_MANY_RESULTS))
+			}
+			packages = append(packages, page...)
+			if len(page) == 0 || offset+hubPackagePageLimit >= totalCount {
+				break
+			}
+		}
 
-	reqUrl := fmt.Sprintf("%v%v", config.Env.ArtifactHubUrl, config.Env.ArtifactHubPackageSearch) + params
-	respData, err := getRequestData(reqUrl, true)
-	if err != nil {
-		return common.RespErr(c, err)
+		itemCount, resultData := ResourceListProcessing(packages, lse)
+		return common.ListRespOK(c, itemCount, resultData)
 	}
 
-	var artifactPackageList artifactPackageList
-	if err := json.Unmarshal(respData.Data, &artifactPackageList); err != nil {
+	packages, totalCount, err := searchHubPackages(query, repo, lse.start, lse.Limit)
+	if err != nil {
 		return common.RespErr(c, err)
 	}
 
-	remainingItemCount := respData.TotalCount - ((lse.Offset + 1) * lse.Limit)
+	remainingItemCount := totalCount - (lse.start + lse.Limit)
 	if remainingItemCount < 0 {
 		remainingItemCount = 0
 	}
 	listCount := common.ListCount{
-		AllItemCount:       respData.TotalCount,
+		AllItemCount:       totalCount,
 		RemainingItemCount: remainingItemCount,
 	}
+	if remainingItemCount > 0 {
+		listCount.Continue = listContinue(lse, lse.start+lse.Limit)
+	}
+
+	packages = projectResourceList(packages, lse)
+	return common.ListRespOK(c, listCount, packages)
+}
+
+// searchHubPackages returns a page of the artifact hub packages and the count of all the matching packages
+func searchHubPackages(query string, repo string, offset int, limit int) ([]interface{}, int, error) {
+	params := fmt.Sprintf("&offset=%v&limit=%v&ts_query_web=%v", offset, limit, url.QueryEscape(query))
+	if len(repo) > 0 {
+		params += "&repo=" + url.QueryEscape(repo)
+	}
+
+	reqUrl := fmt.Sprintf("%v%v", config.Env.ArtifactHubUrl, config.Env.ArtifactHubPackageSearch) + params
+	respData, err := getRequestData(reqUrl, true)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var artifactPackageList artifactPackageList
+	if err := json.Unmarshal(respData.Data, &artifactPackageList); err != nil {
+		return nil, 0, err
+	}
 
 	packages := make([]interface{}, 0, len(artifactPackageList.Packages))
 	for _, re := range artifactPackageList.Packages {
@@ -147,8 +192,7 @@ func SearchPackageHub(c *fiber.Ctx) error {
 		}
 		packages = append(packages, re)
 	}
-
-	return common.ListRespOK(c, listCount, packages)
+	return packages, respData.TotalCount, nil
 }
 
 // GetHelmPackageInfo
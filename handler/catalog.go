@@ -0,0 +1,380 @@
+package handler
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/cmd/helm/search"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	chartSortRelevance = "relevance"
+	chartSortName      = "name"
+
+	// maintainers are not part of the search index lines, their matches rank after the keywords
+	maintainerMatchScore = 4
+
+	// the recorded schema flags are kept in the repository cache so they outlive the process
+	chartSchemaFileName = "chart-schemas.json"
+)
+
+// sort presets of the catalog search, any other sort value is sorted by the list fields
+var chartSortPresets = map[string]string{
+	chartSortRelevance: "score,name,repoName",
+	chartSortName:      "name,repoName",
+	"updated":          "-created,name,repoName",
+}
+
+// chartSchemas keeps whether a chart version has a values schema by its archive digest,
+// the index file does not tell so it is recorded when the archive is downloaded
+var (
+	chartSchemas      sync.Map
+	chartSchemasOnce  sync.Once
+	chartSchemasMutex sync.Mutex
+)
+
+type chartSearchElement struct {
+	Keyword    string
+	Repo       string
+	Version    string
+	AppVersion string
+	Type       string
+	Deprecated *bool
+	HasSchema  *bool
+}
+
+type catalogChartElement struct {
+	Name        string    `json:"name"`
+	Version     string    `json:"version"`
+	AppVersion  string    `json:"app_version"`
+	Description string    `json:"description"`
+	Home        string    `json:"home"`
+	Icon        string    `json:"icon"`
+	Keywords    []string  `json:"keywords"`
+	Maintainers []string  `json:"maintainers"`
+	Type        string    `json:"type"`
+	RepoName    string    `json:"repoName"`
+	Deprecated  bool      `json:"deprecated"`
+	Created     time.Time `json:"created"`
+	Score       int       `json:"score"`
+	// HasSchema is null for the versions whose archive was never downloaded
+	HasSchema *bool `json:"hasSchema"`
+}
+
+// SearchCharts
+// @Summary Search Charts
+// @Tags Charts
+// @Accept json
+// @Produce json
+// @Router /api/charts [Get]
+func SearchCharts(c *fiber.Ctx) error {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	cse, err := chartSearchCheck(c, lse)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	index, err := buildSearchIndexAll()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	res := searchChartIndex(index, cse.Keyword)
+	search.SortScore(res)
+	// the best matching, newest version of each chart
+	data, err := applyConstraint(cse.Version, false, res)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	charts := make([]interface{}, 0, len(data))
+	for _, r := range data {
+		repoName, _, _ := strings.Cut(r.Name, "/")
+		if !matchChartFilters(cse, repoName, r.Chart) {
+			continue
+		}
+		charts = append(charts, newCatalogChartElement(repoName, r))
+	}
+
+	itemCount, resultData := ResourceListProcessing(charts, lse)
+	return common.ListRespOK(c, itemCount, resultData)
+}
+
+func chartSearchCheck(c *fiber.Ctx, lse *ListSearchElement) (*chartSearchElement, error) {
+	cse := &chartSearchElement{
+		Keyword:    strings.TrimSpace(c.Query("q")),
+		Repo:       c.Query("repo"),
+		Version:    c.Query("version", ">0.0.0"),
+		AppVersion: strings.TrimSpace(c.Query("appVersion")),
+		Type:       c.Query("type"),
+	}
+
+	var err error
+	if cse.Deprecated, err = queryOptionalBool(c, "deprecated"); err != nil {
+		return nil, err
+	}
+	if cse.HasSchema, err = queryOptionalBool(c, "hasSchema"); err != nil {
+		return nil, err
+	}
+
+	sortBy := c.Query("sort")
+	if sortBy == "" {
+		sortBy = chartSortName
+		if cse.Keyword != "" {
+			sortBy = chartSortRelevance
+		}
+	}
+	if preset, ok := chartSortPresets[sortBy]; ok {
+		lse.Sort, _ = parseListSort(preset)
+		return cse, nil
+	}
+	fields := listItemFields(catalogChartElement{})
+	for _, sf := range lse.Sort {
+		if _, ok := fields[sf.Field]; !ok {
+			return nil, fmt.Errorf(common.CHART_SORT_INVALID)
+		}
+	}
+	return cse, nil
+}
+
+func queryOptionalBool(c *fiber.Ctx, key string) (*bool, error) {
+	value := c.Query(key)
+	if value == "" {
+		return nil, nil
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return nil, fmt.Errorf(common.CHART_FILTER_INVALID)
+	}
+	return &b, nil
+}
+
+// searchChartIndex searches the chart names, descriptions and keywords of the index and the chart maintainers,
+// all charts are returned without a keyword
+func searchChartIndex(index *search.Index, keyword string) []*search.Result {
+	if keyword == "" {
+		return index.All()
+	}
+
+	res := index.SearchLiteral(keyword, searchMaxScore)
+	found := map[*repo.ChartVersion]bool{}
+	for _, r := range res {
+		found[r.Chart] = true
+	}
+
+	keyword = strings.ToLower(keyword)
+	for _, r := range index.All() {
+		if found[r.Chart] {
+			continue
+		}
+		for _, m := range r.Chart.Maintainers {
+			if m != nil && (strings.Contains(strings.ToLower(m.Name), keyword) || strings.Contains(strings.ToLower(m.Email), keyword)) {
+				r.Score = maintainerMatchScore
+				res = append(res, r)
+				break
+			}
+		}
+	}
+	return res
+}
+
+func matchChartFilters(cse *chartSearchElement, repoName string, cv *repo.ChartVersion) bool {
+	if cse.Repo != "" && cse.Repo != repoName {
+		return false
+	}
+	if cse.Deprecated != nil && *cse.Deprecated != cv.Deprecated {
+		return false
+	}
+	if cse.Type != "" && cse.Type != chartType(cv) {
+		return false
+	}
+	if cse.AppVersion != "" && !matchAppVersion(cse.AppVersion, cv.AppVersion) {
+		return false
+	}
+	// the versions whose schema is unknown are kept, they are returned with a null hasSchema
+	if cse.HasSchema != nil {
+		if hasSchema, known := chartHasSchema(cv); known && hasSchema != *cse.HasSchema {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAppVersion matches the app version by semver constraint, or literally when either is not semver
+func matchAppVersion(filter string, appVersion string) bool {
+	if constraint, err := semver.NewConstraint(filter); err == nil {
+		if v, err := semver.NewVersion(appVersion); err == nil {
+			return constraint.Check(v)
+		}
+	}
+	return strings.TrimPrefix(filter, "v") == strings.TrimPrefix(appVersion, "v")
+}
+
+// chartHasSchema reports whether the chart version has a values.schema.json, it is only known for the versions
+// that were downloaded before so the search never downloads charts
+func chartHasSchema(cv *repo.ChartVersion) (bool, bool) {
+	if cv.Digest == "" {
+		return false, false
+	}
+	chartSchemasOnce.Do(loadChartSchemas)
+	hasSchema, ok := chartSchemas.Load(cv.Digest)
+	if !ok {
+		return false, false
+	}
+	return hasSchema.(bool), true
+}
+
+// recordChartSchema records whether a downloaded chart archive has a values schema, by the digest of the archive
+// which is the digest of the chart version in the index file
+func recordChartSchema(cp string) {
+	digest, err := provenance.DigestFile(cp)
+	if err != nil {
+		log.Errorf("recordChartSchema:: path: %s :: %v", cp, err)
+		return
+	}
+	chartSchemasOnce.Do(loadChartSchemas)
+	if _, ok := chartSchemas.Load(digest); ok {
+		return
+	}
+	hasSchema, err := archiveHasSchema(cp)
+	if err != nil {
+		log.Errorf("recordChartSchema:: path: %s :: %v", cp, err)
+		return
+	}
+	chartSchemas.Store(digest, hasSchema)
+	if err := saveChartSchemas(); err != nil {
+		log.Errorf("recordChartSchema:: save :: %v", err)
+	}
+}
+
+func chartSchemaFile() string {
+	return filepath.Join(settings.RepositoryCache, chartSchemaFileName)
+}
+
+// loadChartSchemas loads the schema flags recorded before the process started
+func loadChartSchemas() {
+	data, err := os.ReadFile(chartSchemaFile())
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		log.Errorf("loadChartSchemas:: %v", err)
+		return
+	}
+	schemas := map[string]bool{}
+	if err := json.Unmarshal(data, &schemas); err != nil {
+		log.Errorf("loadChartSchemas:: %v", err)
+		return
+	}
+	for digest, hasSchema := range schemas {
+		chartSchemas.Store(digest, hasSchema)
+	}
+}
+
+// saveChartSchemas writes the recorded schema flags, through a temporary file so a reader never sees a partial file
+func saveChartSchemas() error {
+	chartSchemasMutex.Lock()
+	defer chartSchemasMutex.Unlock()
+
+	schemas := map[string]bool{}
+	chartSchemas.Range(func(key, value interface{}) bool {
+		schemas[key.(string)] = value.(bool)
+		return true
+	})
+	data, err := json.Marshal(schemas)
+	if err != nil {
+		return err
+	}
+	tmp := chartSchemaFile() + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, chartSchemaFile())
+}
+
+// archiveHasSchema looks for the values.schema.json of the chart in the archive without loading the chart
+func archiveHasSchema(cp string) (bool, error) {
+	f, err := os.Open(cp)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		return false, err
+	}
+	defer gz.Close()
+
+	tr := tar.NewReader(gz)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		// the files of the chart are under its top directory, the schemas of the subcharts are deeper
+		parts := strings.Split(path.Clean(header.Name), "/")
+		if len(parts) == 2 && parts[1] == chartutil.SchemafileName {
+			return true, nil
+		}
+	}
+}
+
+func chartType(cv *repo.ChartVersion) string {
+	if cv.Type == "" {
+		return "application"
+	}
+	return cv.Type
+}
+
+func newCatalogChartElement(repoName string, r *search.Result) catalogChartElement {
+	cv := r.Chart
+	element := catalogChartElement{
+		Name:        cv.Name,
+		Version:     cv.Version,
+		AppVersion:  cv.AppVersion,
+		Description: cv.Description,
+		Home:        cv.Home,
+		Icon:        cv.Icon,
+		Keywords:    cv.Keywords,
+		Maintainers: make([]string, 0, len(cv.Maintainers)),
+		Type:        chartType(cv),
+		RepoName:    repoName,
+		Deprecated:  cv.Deprecated,
+		Created:     cv.Created,
+		Score:       r.Score,
+	}
+	if hasSchema, known := chartHasSchema(cv); known {
+		element.HasSchema = &hasSchema
+	}
+	if element.Keywords == nil {
+		element.Keywords = make([]string, 0)
+	}
+	for _, m := range cv.Maintainers {
+		if m != nil {
+			element.Maintainers = append(element.Maintainers, m.Name)
+		}
+	}
+	return element
+}
@@ -11,8 +11,8 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
-	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/repo"
+	"os"
 	"path/filepath"
 	"strings"
 )
@@ -22,11 +22,29 @@ const searchMaxScore = 25
 
 var readmeFileNames = []string{"readme.md", "readme.txt", "readme"}
 
+// chartInfoSignature reports the provenance verification result of the chart
+const chartInfoSignature = "signature"
+
+// content types of chart files, others are served as plain text
+var chartFileContentTypes = map[string]string{
+	".yaml": "application/x-yaml",
+	".yml":  "application/x-yaml",
+	".json": "application/json",
+	".md":   "text/markdown; charset=utf-8",
+	".tgz":  "application/gzip",
+}
+
 type file struct {
 	Name string `json:"name"`
 	Data string `json:"data"`
 }
 
+// chartInfoAll is the info=all response, the files of the chart with its provenance verification result
+type chartInfoAll struct {
+	Files     []*file         `json:"files"`
+	Signature *chartSignature `json:"signature"`
+}
+
 type repoChartElement struct {
 	Name        string `json:"name"`
 	Version     string `json:"version"`
@@ -113,7 +131,7 @@ func GetChartInfo(c *fiber.Ctx) error {
 	repoName := c.Params("repositories")
 	charts := c.Params("charts") // search keyword
 	version := c.Query("version")
-	info := c.Query("info") // all, readme, values, chart
+	info := c.Query("info") // all, readme, values, chart, signature
 
 	if info == "" {
 		info = string(action.ShowAll)
@@ -130,12 +148,14 @@ func GetChartInfo(c *fiber.Ctx) error {
 		client.OutputFormat = action.ShowValues
 	} else if info == string(action.ShowAll) {
 		client.OutputFormat = action.ShowAll
-	} else {
-		return common.RespErr(c, fmt.Errorf("chart info only support readme/values/chart"))
+	} else if info != chartInfoSignature {
+		return common.RespErr(c, fmt.Errorf("chart info only support readme/values/chart/signature"))
 	}
 
-	aimChart := fmt.Sprintf("%s/%s", repoName, charts)
-	cp, err := client.ChartPathOptions.LocateChart(aimChart, settings)
+	cp, signature, err := locateRepoChart(&client.ChartPathOptions, repoName, charts)
+	if info == chartInfoSignature && signature != nil {
+		return common.RespOK(c, signature)
+	}
 	if err != nil {
 		return common.RespErr(c, err)
 	}
@@ -170,11 +190,82 @@ func GetChartInfo(c *fiber.Ctx) error {
 			})
 		}
 
-		return common.RespOK(c, values)
+		return common.RespOK(c, chartInfoAll{Files: values, Signature: signature})
 	}
 	return common.RespOK(c, nil)
 }
 
+// GetChartPackage
+// @Summary Download Chart Package
+// @Tags Repository
+// @Accept json
+// @Produce octet-stream
+// @Router /api/repositories/:repositories/charts/:charts/package [Get]
+func GetChartPackage(c *fiber.Ctx) error {
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	f, err := os.Open(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return common.RespErr(c, err)
+	}
+
+	c.Attachment(filepath.Base(cp))
+	c.Set(fiber.HeaderContentType, chartFileContentTypes[".tgz"])
+	return c.SendStream(f, int(info.Size()))
+}
+
+// GetChartFile
+// @Summary Get Chart File
+// @Tags Repository
+// @Accept json
+// @Produce plain
+// @Router /api/repositories/:repositories/charts/:charts/files/* [Get]
+func GetChartFile(c *fiber.Ctx) error {
+	name := strings.Trim(c.Params("*"), "/")
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	// without a file name, list the files of the chart
+	if name == "" {
+		names := make([]string, 0, len(chrt.Raw))
+		for _, v := range chrt.Raw {
+			names = append(names, v.Name)
+		}
+		return common.RespOK(c, names)
+	}
+
+	for _, v := range chrt.Raw {
+		if v.Name != name {
+			continue
+		}
+		contentType, ok := chartFileContentTypes[strings.ToLower(filepath.Ext(v.Name))]
+		if !ok {
+			contentType = fiber.MIMETextPlainCharsetUTF8
+		}
+		c.Set(fiber.HeaderContentType, contentType)
+		return c.Send(v.Data)
+	}
+
+	return common.RespErr(c, fmt.Errorf(common.CHART_FILE_NOT_FOUND))
+}
+
 func findReadme(files []*chart.File) (file *chart.File) {
 	for _, file := range files {
 		for _, n := range readmeFileNames {
@@ -190,15 +281,7 @@ func findReadme(files []*chart.File) (file *chart.File) {
 }
 
 func buildSearchIndex(repoName string) (*search.Index, error) {
-	index := search.NewIndex()
-	path := filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(repoName))
-	indexFile, err := repo.LoadIndexFile(path)
-	if err != nil {
-		return nil, fmt.Errorf(common.REPO_CORRUPT_MISSING)
-	}
-
-	index.AddRepo(repoName, indexFile, true)
-	return index, nil
+	return indexCache.repoIndex(repoName)
 }
 
 func buildSearchIndexAll() (*search.Index, error) {
@@ -212,18 +295,11 @@ func buildSearchIndexAll() (*search.Index, error) {
 		return nil, fmt.Errorf(common.REPO_NO_CONFIGURED)
 	}
 
-	index := search.NewIndex()
-
+	repoNames := make([]string, 0, len(repos.Repositories))
 	for _, re := range repos.Repositories {
-		repoName := re.Name
-		f := filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(repoName))
-		indexFile, err := repo.LoadIndexFile(f)
-		if err != nil {
-			continue
-		}
-		index.AddRepo(repoName, indexFile, true)
+		repoNames = append(repoNames, re.Name)
 	}
-	return index, nil
+	return indexCache.allIndex(repoNames), nil
 }
 
 func applyConstraint(version string, versions bool, res []*search.Result) ([]*search.Result, error) {
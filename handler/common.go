@@ -11,6 +11,7 @@ import (
 	"helm.sh/helm/v3/pkg/cli"
 	"io"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	v1 "k8s.io/apimachinery/pkg/apis/testapigroup/v1"
 	"k8s.io/apimachinery/pkg/util/yaml"
 	"os"
@@ -29,11 +30,20 @@ type ListSearchElement struct {
 	Offset     int
 	Limit      int
 	SearchName string
+	Sort       []ListSortField
+	Filters    []ListFilter
+	Fields     []string
+	Continue   string
+	start      int
+	query      uint32
 }
 
 func Settings() {
 	settings.RepositoryConfig = config.Env.HelmRepoConfig
 	settings.RepositoryCache = config.Env.HelmRepoCache
+	loadRepoPolicy()
+	ensureHostedRepo()
+	startDriftSchedule()
 }
 
 func GetResources(out string) []*v1.Carp {
@@ -57,36 +67,47 @@ func GetResources(out string) []*v1.Carp {
 }
 
 func ParseManifests(out string) ([]*v1.Carp, error) {
-	dec := yaml.NewYAMLOrJSONDecoder(strings.NewReader(out), 4096)
-	res := make([]*v1.Carp, 0)
-	var tmp interface{}
-	for {
-		err := dec.Decode(&tmp)
-		if err == io.EOF {
-			break
-		}
-
+	objs, parseErr := ParseManifestObjects(out)
+	res := make([]*v1.Carp, 0, len(objs))
+	for _, obj := range objs {
+		jsoned, err := obj.MarshalJSON()
 		if err != nil {
 			return res, err
 		}
 
-		jsoned, err := json.Marshal(tmp)
+		var doc v1.Carp
+		err = json.Unmarshal(jsoned, &doc)
 		if err != nil {
 			return res, err
 		}
 
-		var doc v1.Carp
-		err = json.Unmarshal(jsoned, &doc)
+		res = append(res, &doc)
+	}
+	return res, parseErr
+}
+
+func ParseManifestObjects(out string) ([]*unstructured.Unstructured, error) {
+	dec := yaml.NewYAMLOrJSONDecoder(strings.NewReader(out), 4096)
+	res := make([]*unstructured.Unstructured, 0)
+	for {
+		var tmp map[string]interface{}
+		err := dec.Decode(&tmp)
+		if err == io.EOF {
+			break
+		}
+
 		if err != nil {
 			return res, err
 		}
 
-		if doc.Kind == "" {
+		doc := &unstructured.Unstructured{Object: tmp}
+		if doc.GetKind() == "" {
+			jsoned, _ := json.Marshal(tmp)
 			log.Warnf("Manifest piece is not k8s resource: %s", jsoned)
 			continue
 		}
 
-		res = append(res, &doc)
+		res = append(res, doc)
 	}
 	return res, nil
 }
@@ -142,25 +163,49 @@ func ListSearchCheck(c *fiber.Ctx) (*ListSearchElement, error) {
 		Offset:     offset,
 		Limit:      limit,
 		SearchName: strings.TrimSpace(c.Query("searchName", "")),
+		Continue:   c.Query("continue"),
+	}
+	if lse.Continue != "" && (offset > 0 || limit == 0) {
+		return nil, fmt.Errorf(common.CONTINUE_REQUIRES_LIMIT_ILLEGAL_ARGUMENT)
+	}
+	if lse.Sort, err = parseListSort(c.Query("sort")); err != nil {
+		return nil, err
+	}
+	if lse.Filters, err = parseListFilter(c.Query("filter")); err != nil {
+		return nil, err
+	}
+	if lse.Fields, err = parseListFields(c.Query("fields")); err != nil {
+		return nil, err
+	}
+	lse.query = listQueryHash(c)
+	if lse.start, err = listPageStart(&lse); err != nil {
+		return nil, err
 	}
 
 	return &lse, nil
 }
 
 func ResourceListProcessing(list []interface{}, lse *ListSearchElement) (common.ListCount, []interface{}) {
-	// 1. search keyword
-	if lse.SearchName != "" {
-		list = searchResourceName(list, lse.SearchName)
-	}
+	listCount, page := resourceListPage(list, lse)
 
-	// 2. paging (offset & limit)
+	// 3. field selection
+	return listCount, projectResourceList(page, lse)
+}
+
+// resourceListPage searches, filters and sorts the list and returns the requested page without the field selection,
+// for lists whose items are completed only for the page returned
+func resourceListPage(list []interface{}, lse *ListSearchElement) (common.ListCount, []interface{}) {
+	// 1. search keyword, filters & sort
+	list = filterSortResourceList(list, lse)
+
+	// 2. paging (offset & limit or continue token)
+	start := lse.start
 	allItemCount := len(list)
 	if allItemCount < 1 {
 		return common.ListCount{}, make([]interface{}, 0)
 	}
 
-	remainingItemCount := allItemCount - ((lse.Offset + 1) * lse.Limit)
-	start := lse.Offset * lse.Limit
+	remainingItemCount := allItemCount - (start + lse.Limit)
 
 	if lse.Limit == 0 || remainingItemCount < 0 {
 		remainingItemCount = 0
@@ -170,6 +215,9 @@ func ResourceListProcessing(list []interface{}, lse *ListSearchElement) (common.
 		AllItemCount:       allItemCount,
 		RemainingItemCount: remainingItemCount,
 	}
+	if remainingItemCount > 0 {
+		listCount.Continue = listContinue(lse, start+lse.Limit)
+	}
 
 	if lse.Limit == 0 {
 		return listCount, list
@@ -187,7 +235,7 @@ func ResourceListProcessing(list []interface{}, lse *ListSearchElement) (common.
 func searchResourceName(list []interface{}, searchName string) []interface{} {
 	var searchList []interface{}
 	for _, re := range list {
-		name := reflect.ValueOf(re).FieldByName("Name").String()
+		name := reflect.Indirect(reflect.ValueOf(re)).FieldByName("Name").String()
 		if strings.Contains(name, searchName) {
 			searchList = append(searchList, re)
 		}
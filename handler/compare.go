@@ -0,0 +1,382 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// line diffs of larger files are skipped, the diff time grows with the line count and the number of changes
+const maxLineDiffLines = 10000
+
+type chartComparison struct {
+	From         string          `json:"from"`
+	To           string          `json:"to"`
+	Metadata     []valueChange   `json:"metadata"`
+	Values       changeSet       `json:"values"`
+	Templates    filesComparison `json:"templates"`
+	CRDs         filesComparison `json:"crds"`
+	Dependencies changeSet       `json:"dependencies"`
+}
+
+type changeSet struct {
+	Added   []valueChange `json:"added"`
+	Removed []valueChange `json:"removed"`
+	Changed []valueChange `json:"changed"`
+}
+
+type valueChange struct {
+	Path string      `json:"path"`
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+type filesComparison struct {
+	Added   []string     `json:"added"`
+	Removed []string     `json:"removed"`
+	Changed []fileChange `json:"changed"`
+}
+
+type fileChange struct {
+	Name    string `json:"name"`
+	Diff    string `json:"diff"`
+	Skipped bool   `json:"skipped"`
+}
+
+// CompareChartVersions
+// @Summary Compare Chart Versions
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/compare [Get]
+func CompareChartVersions(c *fiber.Ctx) error {
+	repoName := c.Params("repositories")
+	charts := c.Params("charts")
+	from, to := c.Query("from"), c.Query("to")
+	if from == "" || to == "" {
+		return common.RespErr(c, fmt.Errorf(common.COMPARE_VERSIONS_REQUIRED))
+	}
+
+	fromChart, err := loadRepoChartVersion(repoName, charts, from)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	toChart, err := loadRepoChartVersion(repoName, charts, to)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	return common.RespOK(c, compareCharts(fromChart, toChart))
+}
+
+func loadRepoChartVersion(repoName string, chartName string, version string) (*chart.Chart, error) {
+	opts := &action.ChartPathOptions{Version: version}
+	cp, _, err := locateRepoChart(opts, repoName, chartName)
+	if err != nil {
+		return nil, err
+	}
+	return loader.Load(cp)
+}
+
+func compareCharts(from *chart.Chart, to *chart.Chart) *chartComparison {
+	comparison := &chartComparison{
+		From:         from.Metadata.Version,
+		To:           to.Metadata.Version,
+		Metadata:     compareMetadata(from.Metadata, to.Metadata),
+		Values:       newChangeSet(),
+		Templates:    compareFiles(from.Templates, to.Templates),
+		CRDs:         compareFiles(from.CRDs(), to.CRDs()),
+		Dependencies: newChangeSet(),
+	}
+	compareValues("$", from.Values, to.Values, &comparison.Values)
+
+	fromDeps := dependenciesByKey(from.Metadata.Dependencies)
+	toDeps := dependenciesByKey(to.Metadata.Dependencies)
+	for _, key := range unionKeys(fromDeps, toDeps) {
+		f, fok := fromDeps[key]
+		t, tok := toDeps[key]
+		switch {
+		case !fok:
+			comparison.Dependencies.Added = append(comparison.Dependencies.Added, valueChange{Path: key, To: t})
+		case !tok:
+			comparison.Dependencies.Removed = append(comparison.Dependencies.Removed, valueChange{Path: key, From: f})
+		case !reflect.DeepEqual(f, t):
+			comparison.Dependencies.Changed = append(comparison.Dependencies.Changed, valueChange{Path: key, From: f, To: t})
+		}
+	}
+	return comparison
+}
+
+// compareMetadata compares the Chart.yaml fields, dependencies are compared separately
+func compareMetadata(from *chart.Metadata, to *chart.Metadata) []valueChange {
+	fromFields, toFields := metadataFields(from), metadataFields(to)
+	changes := make([]valueChange, 0)
+	for _, key := range unionKeys(fromFields, toFields) {
+		if key == "dependencies" {
+			continue
+		}
+		if !reflect.DeepEqual(fromFields[key], toFields[key]) {
+			changes = append(changes, valueChange{Path: key, From: fromFields[key], To: toFields[key]})
+		}
+	}
+	return changes
+}
+
+func metadataFields(metadata *chart.Metadata) map[string]interface{} {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(metadata)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+// compareValues reports the added, removed and changed keys of the default values,
+// nested maps are compared key by key and any other value as a whole
+func compareValues(path string, from map[string]interface{}, to map[string]interface{}, changes *changeSet) {
+	for _, key := range unionKeys(from, to) {
+		keyPath := jsonPath(path, key)
+		f, fok := from[key]
+		t, tok := to[key]
+		switch {
+		case !fok:
+			changes.Added = append(changes.Added, valueChange{Path: keyPath, To: t})
+		case !tok:
+			changes.Removed = append(changes.Removed, valueChange{Path: keyPath, From: f})
+		default:
+			fromMap, fromIsMap := f.(map[string]interface{})
+			toMap, toIsMap := t.(map[string]interface{})
+			if fromIsMap && toIsMap {
+				compareValues(keyPath, fromMap, toMap, changes)
+			} else if !reflect.DeepEqual(f, t) {
+				changes.Changed = append(changes.Changed, valueChange{Path: keyPath, From: f, To: t})
+			}
+		}
+	}
+}
+
+func compareFiles(from []*chart.File, to []*chart.File) filesComparison {
+	comparison := filesComparison{
+		Added:   make([]string, 0),
+		Removed: make([]string, 0),
+		Changed: make([]fileChange, 0),
+	}
+	fromFiles, toFiles := filesByName(from), filesByName(to)
+	for _, name := range unionKeys(fromFiles, toFiles) {
+		f, fok := fromFiles[name]
+		t, tok := toFiles[name]
+		switch {
+		case !fok:
+			comparison.Added = append(comparison.Added, name)
+		case !tok:
+			comparison.Removed = append(comparison.Removed, name)
+		case f != t:
+			diff, ok := diffLines(f, t)
+			comparison.Changed = append(comparison.Changed, fileChange{Name: name, Diff: diff, Skipped: !ok})
+		}
+	}
+	return comparison
+}
+
+// diffLines returns the changed lines of two file contents, grouped in hunks without context lines,
+// it returns false when the files are too large to be compared line by line
+func diffLines(from string, to string) (string, bool) {
+	a, b := strings.Split(from, "\n"), strings.Split(to, "\n")
+	if len(a)+len(b) > maxLineDiffLines {
+		return "", false
+	}
+
+	d := &lineDiff{a: a, b: b, deleted: make([]bool, len(a)), inserted: make([]bool, len(b))}
+	d.compare(0, len(a), 0, len(b))
+
+	var sb strings.Builder
+	inHunk := false
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		if i < len(a) && j < len(b) && !d.deleted[i] && !d.inserted[j] {
+			inHunk = false
+			i++
+			j++
+			continue
+		}
+		if !inHunk {
+			fmt.Fprintf(&sb, "@@ -%d +%d @@\n", i+1, j+1)
+			inHunk = true
+		}
+		if i < len(a) && d.deleted[i] {
+			fmt.Fprintf(&sb, "-%s\n", a[i])
+			i++
+		} else {
+			fmt.Fprintf(&sb, "+%s\n", b[j])
+			j++
+		}
+	}
+	return sb.String(), true
+}
+
+// lineDiff marks the deleted lines of a and the inserted lines of b with the linear space variant of the myers diff,
+// each range is split at the middle of its shortest edit script until only deletions or insertions are left
+type lineDiff struct {
+	a        []string
+	b        []string
+	deleted  []bool
+	inserted []bool
+}
+
+func (d *lineDiff) compare(aLo int, aHi int, bLo int, bHi int) {
+	for aLo < aHi && bLo < bHi && d.a[aLo] == d.b[bLo] {
+		aLo++
+		bLo++
+	}
+	for aLo < aHi && bLo < bHi && d.a[aHi-1] == d.b[bHi-1] {
+		aHi--
+		bHi--
+	}
+
+	switch {
+	case aLo == aHi:
+		for j := bLo; j < bHi; j++ {
+			d.inserted[j] = true
+		}
+	case bLo == bHi:
+		for i := aLo; i < aHi; i++ {
+			d.deleted[i] = true
+		}
+	default:
+		x, y, ok := d.middleSnake(aLo, aHi, bLo, bHi)
+		if !ok || (x == aLo && y == bLo) || (x == aHi && y == bHi) {
+			d.compare(aLo, aHi, bLo, bLo)
+			d.compare(aLo, aLo, bLo, bHi)
+			return
+		}
+		d.compare(aLo, x, bLo, y)
+		d.compare(x, aHi, y, bHi)
+	}
+}
+
+// middleSnake searches the shortest edit script from both ends at once and returns where the two searches meet
+func (d *lineDiff) middleSnake(aLo int, aHi int, bLo int, bHi int) (int, int, bool) {
+	n, m := aHi-aLo, bHi-bLo
+	maxD := (n + m + 1) / 2
+	offset := maxD
+	// forward[k] and backward[k] are the furthest x reached on diagonal k from the start and from the end
+	forward, backward := make([]int, 2*maxD+2), make([]int, 2*maxD+2)
+	for i := range forward {
+		forward[i], backward[i] = -1, -1
+	}
+	forward[offset+1], backward[offset+1] = 0, 0
+
+	delta := n - m
+	// the forward search meets the backward one on odd deltas, the backward search on even deltas
+	front := delta%2 != 0
+	kStart, kEnd, k2Start, k2End := 0, 0, 0, 0
+	for step := 0; step < maxD; step++ {
+		for k := -step + kStart; k <= step-kEnd; k += 2 {
+			var x int
+			if k == -step || (k != step && forward[offset+k-1] < forward[offset+k+1]) {
+				x = forward[offset+k+1]
+			} else {
+				x = forward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aLo+x] == d.b[bLo+y] {
+				x++
+				y++
+			}
+			forward[offset+k] = x
+			switch {
+			case x > n:
+				kEnd += 2
+			case y > m:
+				kStart += 2
+			case front:
+				k2 := offset + delta - k
+				if k2 >= 0 && k2 < len(backward) && backward[k2] != -1 && x >= n-backward[k2] {
+					return aLo + x, bLo + y, true
+				}
+			}
+		}
+
+		for k := -step + k2Start; k <= step-k2End; k += 2 {
+			var x int
+			if k == -step || (k != step && backward[offset+k-1] < backward[offset+k+1]) {
+				x = backward[offset+k+1]
+			} else {
+				x = backward[offset+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[aHi-x-1] == d.b[bHi-y-1] {
+				x++
+				y++
+			}
+			backward[offset+k] = x
+			switch {
+			case x > n:
+				k2End += 2
+			case y > m:
+				k2Start += 2
+			case !front:
+				k1 := offset + delta - k
+				if k1 >= 0 && k1 < len(forward) && forward[k1] != -1 {
+					x1 := forward[k1]
+					y1 := offset + x1 - k1
+					if x1 >= n-x {
+						return aLo + x1, bLo + y1, true
+					}
+				}
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func newChangeSet() changeSet {
+	return changeSet{
+		Added:   make([]valueChange, 0),
+		Removed: make([]valueChange, 0),
+		Changed: make([]valueChange, 0),
+	}
+}
+
+// dependenciesByKey maps the dependencies by their values key (alias or name)
+func dependenciesByKey(deps []*chart.Dependency) map[string]*chart.Dependency {
+	byKey := map[string]*chart.Dependency{}
+	for _, d := range deps {
+		key := d.Name
+		if d.Alias != "" {
+			key = d.Alias
+		}
+		byKey[key] = d
+	}
+	return byKey
+}
+
+func filesByName(files []*chart.File) map[string]string {
+	byName := map[string]string{}
+	for _, f := range files {
+		byName[f.Name] = string(f.Data)
+	}
+	return byName
+}
+
+func unionKeys[V any](a map[string]V, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,251 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/repo"
+	"strings"
+)
+
+const (
+	dependencyVendored   = "vendored"
+	dependencyMismatched = "mismatched"
+	dependencyMissing    = "missing"
+	dependencyUnresolved = "unresolved"
+)
+
+type chartDependency struct {
+	Name            string            `json:"name"`
+	Alias           string            `json:"alias"`
+	Version         string            `json:"version"`
+	ResolvedVersion string            `json:"resolved_version"`
+	Repository      string            `json:"repository"`
+	RepositoryName  string            `json:"repository_name"`
+	Condition       string            `json:"condition"`
+	Tags            []string          `json:"tags"`
+	Status          string            `json:"status"`
+	Dependencies    []chartDependency `json:"dependencies"`
+}
+
+// GetChartDependencies
+// @Summary Get Chart Dependencies
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/dependencies [Get]
+func GetChartDependencies(c *fiber.Ctx) error {
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	repoFile, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		return common.RespErr(c, fmt.Errorf(common.REPO_FAILED_LOADING_FILE))
+	}
+
+	return common.RespOK(c, dependencyTree(chrt, repoFile))
+}
+
+// dependencyTree lists the dependencies declared in Chart.yaml, vendored ones with their own dependencies
+// and missing ones with the version that would be resolved from the configured repositories and its dependencies
+func dependencyTree(chrt *chart.Chart, repoFile *repo.File) []chartDependency {
+	return dependencyNodes(chrt.Metadata.Dependencies, chrt.Dependencies(), repoFile, map[string]bool{})
+}
+
+// dependencyNodes builds the tree of the declared dependencies, path holds the charts above them
+// so a repository index that has a dependency cycle does not recurse forever
+func dependencyNodes(declared []*chart.Dependency, vendored []*chart.Chart, repoFile *repo.File, path map[string]bool) []chartDependency {
+	deps := make([]chartDependency, 0, len(declared))
+	for _, d := range declared {
+		dep := chartDependency{
+			Name:         d.Name,
+			Alias:        d.Alias,
+			Version:      d.Version,
+			Repository:   d.Repository,
+			Condition:    d.Condition,
+			Tags:         d.Tags,
+			Dependencies: make([]chartDependency, 0),
+		}
+		if dep.Tags == nil {
+			dep.Tags = make([]string, 0)
+		}
+		if entry := findDependencyRepo(repoFile, d.Repository); entry != nil {
+			dep.RepositoryName = entry.Name
+		}
+
+		if sub := vendoredDependency(vendored, d); sub != nil {
+			dep.ResolvedVersion = sub.Metadata.Version
+			dep.Status = dependencyVendored
+			if !versionSatisfies(d.Version, sub.Metadata.Version) {
+				dep.Status = dependencyMismatched
+			}
+			dep.Dependencies = dependencyNodes(sub.Metadata.Dependencies, sub.Dependencies(), repoFile, path)
+		} else if cv := resolveDependencyVersion(dep.RepositoryName, d); cv != nil {
+			dep.ResolvedVersion = cv.Version
+			dep.Status = dependencyMissing
+			// the index keeps the Chart.yaml of each version, so the dependencies of a missing chart are known
+			key := dep.RepositoryName + "/" + cv.Name + "@" + cv.Version
+			if !path[key] {
+				path[key] = true
+				dep.Dependencies = dependencyNodes(cv.Dependencies, nil, repoFile, path)
+				delete(path, key)
+			}
+		} else {
+			dep.Status = dependencyUnresolved
+		}
+		deps = append(deps, dep)
+	}
+	return deps
+}
+
+// vendoredDependency finds the vendored chart of a dependency, aliases of one chart may vendor several versions
+// so the version that satisfies the dependency is preferred
+func vendoredDependency(vendored []*chart.Chart, d *chart.Dependency) *chart.Chart {
+	var found *chart.Chart
+	for _, sub := range vendored {
+		if sub.Name() != d.Name {
+			continue
+		}
+		if versionSatisfies(d.Version, sub.Metadata.Version) {
+			return sub
+		}
+		if found == nil {
+			found = sub
+		}
+	}
+	return found
+}
+
+// resolveChartDependencies downloads the missing dependencies of a chart from the configured repositories
+// like 'helm dependency update', each one verified by the verification mode of its repository,
+// and returns the chart with them
+func resolveChartDependencies(chrt *chart.Chart) (*chart.Chart, error) {
+	if err := action.CheckDependencies(chrt, chrt.Metadata.Dependencies); err == nil {
+		return chrt, nil
+	}
+
+	repoFile, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		return nil, fmt.Errorf(common.REPO_FAILED_LOADING_FILE)
+	}
+	return resolveMissingDependencies(chrt, repoFile, map[string]bool{chrt.Name() + "@" + chrt.Metadata.Version: true})
+}
+
+// resolveMissingDependencies adds the missing dependencies of the chart, path holds the charts being resolved
+// above it so a dependency cycle is refused instead of resolved forever
+func resolveMissingDependencies(chrt *chart.Chart, repoFile *repo.File, path map[string]bool) (*chart.Chart, error) {
+	if err := action.CheckDependencies(chrt, chrt.Metadata.Dependencies); err == nil {
+		return chrt, nil
+	}
+
+	// aliases of one chart and version share the downloaded chart
+	resolved := map[string]bool{}
+	for _, d := range chrt.Metadata.Dependencies {
+		if vendoredDependency(chrt.Dependencies(), d) != nil {
+			continue
+		}
+		// only configured repositories are used, helm would otherwise add unknown repository urls on the fly
+		entry := findDependencyRepo(repoFile, d.Repository)
+		if entry == nil {
+			log.Errorf("resolveMissingDependencies:: chart: %s, dependency: %s, repository not registered: %s", chrt.Name(), d.Name, d.Repository)
+			return nil, fmt.Errorf(common.DEPENDENCY_REPO_NOT_FOUND)
+		}
+		cv := resolveDependencyVersion(entry.Name, d)
+		if cv == nil {
+			log.Errorf("resolveMissingDependencies:: chart: %s, dependency: %s, no version matches %s", chrt.Name(), d.Name, d.Version)
+			return nil, fmt.Errorf(common.DEPENDENCY_UPDATE_FAILED)
+		}
+		key := cv.Name + "@" + cv.Version
+		if resolved[key] {
+			continue
+		}
+		if path[key] {
+			log.Errorf("resolveMissingDependencies:: chart: %s, dependency cycle through %s", chrt.Name(), key)
+			return nil, fmt.Errorf(common.DEPENDENCY_CYCLE)
+		}
+
+		cp, _, err := locateRepoChart(&action.ChartPathOptions{Version: cv.Version}, entry.Name, d.Name)
+		if err != nil {
+			log.Errorf("resolveMissingDependencies:: chart: %s, dependency: %s :: %v", chrt.Name(), d.Name, err)
+			return nil, err
+		}
+		sub, err := loader.Load(cp)
+		if err != nil {
+			log.Errorf("resolveMissingDependencies:: chart: %s, dependency: %s :: %v", chrt.Name(), d.Name, err)
+			return nil, fmt.Errorf(common.DEPENDENCY_UPDATE_FAILED)
+		}
+		// a downloaded dependency may have missing dependencies of its own
+		path[key] = true
+		sub, err = resolveMissingDependencies(sub, repoFile, path)
+		delete(path, key)
+		if err != nil {
+			return nil, err
+		}
+		chrt.AddDependency(sub)
+		resolved[key] = true
+	}
+
+	if err := action.CheckDependencies(chrt, chrt.Metadata.Dependencies); err != nil {
+		return nil, err
+	}
+	return chrt, nil
+}
+
+// findDependencyRepo finds the configured repository of a dependency repository url or @alias
+func findDependencyRepo(repoFile *repo.File, repository string) *repo.Entry {
+	for _, entry := range repoFile.Repositories {
+		if strings.HasPrefix(repository, "@") || strings.HasPrefix(repository, "alias:") {
+			if strings.TrimPrefix(strings.TrimPrefix(repository, "@"), "alias:") == entry.Name {
+				return entry
+			}
+		} else if strings.TrimSuffix(repository, "/") == strings.TrimSuffix(entry.URL, "/") {
+			return entry
+		}
+	}
+	return nil
+}
+
+// resolveDependencyVersion returns the newest chart version of the cached repository index matching the dependency
+func resolveDependencyVersion(repoName string, d *chart.Dependency) *repo.ChartVersion {
+	if repoName == "" {
+		return nil
+	}
+	indexFile, err := indexCache.repoIndexFile(repoName)
+	if err != nil {
+		log.Errorf("resolveDependencyVersion:: repo: %s :: %v", repoName, err)
+		return nil
+	}
+	cv, err := indexFile.Get(d.Name, d.Version)
+	if err != nil {
+		return nil
+	}
+	return cv
+}
+
+func versionSatisfies(constraint string, version string) bool {
+	if constraint == "" {
+		return true
+	}
+	c, err := semver.NewConstraint(constraint)
+	if err != nil {
+		return false
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return false
+	}
+	return c.Check(v)
+}
@@ -0,0 +1,475 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"go-api/config"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	driftInSync   = "InSync"
+	driftModified = "Modified"
+	driftDeleted  = "Deleted"
+	driftUnknown  = "Unknown"
+
+	// the values of secrets are not reported, only the paths that changed
+	driftRedacted = "<redacted>"
+)
+
+// workloads whose replicas are reported apart from the other fields, autoscalers and kubectl scale change them
+var replicaKinds = map[string]bool{
+	"Deployment":  true,
+	"StatefulSet": true,
+	"ReplicaSet":  true,
+}
+
+// driftReports keeps the last drift report of each release by cluster, namespace and name
+var driftReports sync.Map
+
+type releaseDrift struct {
+	ClusterId string          `json:"clusterId"`
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Revision  int             `json:"revision"`
+	Drifted   bool            `json:"drifted"`
+	CheckedAt string          `json:"checkedAt"`
+	Resources []resourceDrift `json:"resources"`
+}
+
+type resourceDrift struct {
+	Kind      string        `json:"kind"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace"`
+	Status    string        `json:"status"`
+	Message   string        `json:"message,omitempty"`
+	Changes   []valueChange `json:"changes"`
+	Replicas  *replicaDrift `json:"replicas,omitempty"`
+}
+
+type replicaDrift struct {
+	Desired int64 `json:"desired"`
+	Live    int64 `json:"live"`
+	Ready   int64 `json:"ready"`
+}
+
+// GetReleaseDrift
+// @Summary Check Release Drift
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/drift [Get]
+func GetReleaseDrift(c *fiber.Ctx) error {
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	rel, err := action.NewGet(actionConfig).Run(c.Params("release"))
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, err)
+	}
+
+	drift, err := checkReleaseDrift(actionConfig, c.Params("clusterId"), rel)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, drift)
+}
+
+// ListDriftReports
+// @Summary List Last Release Drift Reports
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/releases/drift [Get]
+func ListDriftReports(c *fiber.Ctx) error {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	if len(lse.Sort) == 0 {
+		lse.Sort, _ = parseListSort("-drifted,clusterId,namespace,name")
+	}
+
+	// the reports are made with the cluster tokens, they are scoped as the tokens of the user would be
+	claims := common.UserClaims(c)
+	userTypes := map[string]string{}
+	clusters, err := common.AccessibleClusters(claims)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	for _, clusterId := range clusters {
+		userTypes[clusterId] = common.ClusterUserType(claims, clusterId)
+	}
+
+	reports := make([]*releaseDrift, 0)
+	driftReports.Range(func(_, value interface{}) bool {
+		reports = append(reports, value.(*releaseDrift))
+		return true
+	})
+
+	elements := make([]interface{}, 0)
+	namespaces := map[string]bool{}
+	for _, drift := range reports {
+		switch userTypes[drift.ClusterId] {
+		case common.AUTH_SUPER_ADMIN, common.AUTH_CLUSTER_ADMIN:
+			// the cluster tokens see all the namespaces of the cluster
+		case common.AUTH_USER:
+			// USER tokens are issued per namespace, a report is listed when the user has the token of its namespace
+			key := drift.ClusterId + "/" + drift.Namespace
+			accessible, checked := namespaces[key]
+			if !checked {
+				_, err := common.InitClusterKubeInfo(c.Context(), claims, drift.ClusterId, drift.Namespace)
+				accessible = err == nil
+				namespaces[key] = accessible
+			}
+			if !accessible {
+				continue
+			}
+		default:
+			continue
+		}
+		elements = append(elements, *drift)
+	}
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.ListRespOK(c, itemCount, resultData)
+}
+
+// checkReleaseDrift compares each resource of the release manifest with its live object and keeps the report,
+// only the fields set in the manifest are compared so the fields populated by the server are ignored
+func checkReleaseDrift(actionConfig *action.Configuration, clusterId string, rel *release.Release) (*releaseDrift, error) {
+	infos, err := actionConfig.KubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		log.Errorf("checkReleaseDrift:: release: %s :: %v", rel.Name, err)
+		return nil, err
+	}
+	// the builder has already put the objects without a namespace in the namespace of the client,
+	// which is not the release namespace for the scheduled check, so they are told apart in the raw manifest
+	objects, err := ParseManifestObjects(rel.Manifest)
+	if err != nil {
+		log.Errorf("checkReleaseDrift:: release: %s :: %v", rel.Name, err)
+		return nil, err
+	}
+	withoutNamespace := map[involvedObject]bool{}
+	for _, obj := range objects {
+		if obj.GetNamespace() == "" {
+			withoutNamespace[involvedObject{Kind: obj.GetKind(), Name: obj.GetName()}] = true
+		}
+	}
+
+	drift := &releaseDrift{
+		ClusterId: clusterId,
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		CheckedAt: time.Now().Format(time.DateTime),
+		Resources: make([]resourceDrift, 0, len(infos)),
+	}
+	for _, info := range infos {
+		desired, ok := info.Object.DeepCopyObject().(*unstructured.Unstructured)
+		if !ok {
+			continue
+		}
+		if withoutNamespace[involvedObject{Kind: desired.GetKind(), Name: info.Name}] && info.Mapping.Scope.Name() == meta.RESTScopeNameNamespace {
+			info.Namespace = rel.Namespace
+			desired.SetNamespace(rel.Namespace)
+		}
+
+		rd := resourceDrift{Kind: desired.GetKind(), Name: info.Name, Namespace: info.Namespace, Status: driftInSync, Changes: make([]valueChange, 0)}
+		if err := info.Get(); err != nil {
+			if apierrors.IsNotFound(err) {
+				rd.Status = driftDeleted
+			} else {
+				rd.Status, rd.Message = driftUnknown, err.Error()
+			}
+		} else if live, ok := info.Object.(*unstructured.Unstructured); ok {
+			compareLiveObject(desired, live, &rd)
+		}
+		if rd.Status != driftInSync {
+			drift.Drifted = true
+		}
+		drift.Resources = append(drift.Resources, rd)
+	}
+
+	driftReports.Store(driftReportKey(clusterId, rel.Namespace, rel.Name), drift)
+	return drift, nil
+}
+
+func driftReportKey(clusterId string, namespace string, name string) string {
+	return fmt.Sprintf("%s/%s/%s", clusterId, namespace, name)
+}
+
+func compareLiveObject(desired *unstructured.Unstructured, live *unstructured.Unstructured, rd *resourceDrift) {
+	desiredObject := desired.Object
+	if desired.GetKind() == "Secret" {
+		desiredObject = secretStringDataAsData(desiredObject)
+	}
+
+	if replicaKinds[desired.GetKind()] {
+		desiredReplicas, found, _ := unstructured.NestedInt64(desiredObject, "spec", "replicas")
+		if !found {
+			desiredReplicas = 1
+		}
+		liveReplicas, _, _ := unstructured.NestedInt64(live.Object, "spec", "replicas")
+		readyReplicas, _, _ := unstructured.NestedInt64(live.Object, "status", "readyReplicas")
+		if desiredReplicas != liveReplicas {
+			rd.Replicas = &replicaDrift{Desired: desiredReplicas, Live: liveReplicas, Ready: readyReplicas}
+			rd.Status = driftModified
+		}
+		desiredObject = copyWithoutField(desiredObject, "spec", "replicas")
+	}
+
+	for _, key := range sortedKeys(desiredObject) {
+		// the status is written by the server
+		if key == "status" {
+			continue
+		}
+		compareLiveValue(jsonPath("$", key), desiredObject[key], live.Object[key], &rd.Changes)
+	}
+	if desired.GetKind() == "Secret" {
+		redactSecretChanges(rd.Changes)
+	}
+	if len(rd.Changes) > 0 {
+		rd.Status = driftModified
+	}
+}
+
+// redactSecretChanges hides the values of the changes in the data of a secret, the stringData is compared as data
+func redactSecretChanges(changes []valueChange) {
+	for i, change := range changes {
+		if change.Path != "$.data" && !strings.HasPrefix(change.Path, "$.data.") && !strings.HasPrefix(change.Path, "$.data[") {
+			continue
+		}
+		if change.From != nil {
+			changes[i].From = driftRedacted
+		}
+		if change.To != nil {
+			changes[i].To = driftRedacted
+		}
+	}
+}
+
+// compareLiveValue reports the fields of the desired value that differ in the live value,
+// list items with a name are matched by name and others by their position
+func compareLiveValue(path string, desired interface{}, live interface{}, changes *[]valueChange) {
+	switch d := desired.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		l, ok := live.(map[string]interface{})
+		if !ok {
+			*changes = append(*changes, valueChange{Path: path, From: desired, To: live})
+			return
+		}
+		for _, key := range sortedKeys(d) {
+			// the namespace defaults to the release namespace
+			if path == "$.metadata" && (key == "namespace" || key == "creationTimestamp") {
+				continue
+			}
+			compareLiveValue(jsonPath(path, key), d[key], l[key], changes)
+		}
+	case []interface{}:
+		l, ok := live.([]interface{})
+		if !ok {
+			*changes = append(*changes, valueChange{Path: path, From: desired, To: live})
+			return
+		}
+		compareLiveList(path, d, l, changes)
+	default:
+		if !equalLiveScalar(desired, live) {
+			*changes = append(*changes, valueChange{Path: path, From: desired, To: live})
+		}
+	}
+}
+
+func compareLiveList(path string, desired []interface{}, live []interface{}, changes *[]valueChange) {
+	desiredByName, named := listItemsByName(desired)
+	liveByName, liveNamed := listItemsByName(live)
+	if named && liveNamed {
+		for _, name := range unionKeys(desiredByName, liveByName) {
+			itemPath := fmt.Sprintf("%s[name=%s]", path, name)
+			d, dok := desiredByName[name]
+			l, lok := liveByName[name]
+			switch {
+			case !dok:
+				*changes = append(*changes, valueChange{Path: itemPath, To: l})
+			case !lok:
+				*changes = append(*changes, valueChange{Path: itemPath, From: d})
+			default:
+				compareLiveValue(itemPath, d, l, changes)
+			}
+		}
+		return
+	}
+
+	for i := 0; i < len(desired) || i < len(live); i++ {
+		itemPath := jsonPath(path, strconv.Itoa(i))
+		switch {
+		case i >= len(live):
+			*changes = append(*changes, valueChange{Path: itemPath, From: desired[i]})
+		case i >= len(desired):
+			*changes = append(*changes, valueChange{Path: itemPath, To: live[i]})
+		default:
+			compareLiveValue(itemPath, desired[i], live[i], changes)
+		}
+	}
+}
+
+// listItemsByName maps the list items by their name, when every item is an object with a name
+func listItemsByName(list []interface{}) (map[string]interface{}, bool) {
+	byName := make(map[string]interface{}, len(list))
+	for _, item := range list {
+		m, ok := item.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		name, ok := m["name"].(string)
+		if !ok || name == "" {
+			return nil, false
+		}
+		byName[name] = item
+	}
+	return byName, len(list) > 0
+}
+
+// equalLiveScalar compares numbers by value and quantities by amount, the server normalizes both
+func equalLiveScalar(desired interface{}, live interface{}) bool {
+	if reflect.DeepEqual(desired, live) {
+		return true
+	}
+	if live == nil {
+		return false
+	}
+	ds, ls := fmt.Sprint(desired), fmt.Sprint(live)
+	if ds == ls {
+		return true
+	}
+	dq, err := resource.ParseQuantity(ds)
+	if err != nil {
+		return false
+	}
+	lq, err := resource.ParseQuantity(ls)
+	if err != nil {
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
+
+// secretStringDataAsData moves the stringData of a secret to its data, the server only keeps the data
+func secretStringDataAsData(obj map[string]interface{}) map[string]interface{} {
+	stringData, ok := obj["stringData"].(map[string]interface{})
+	if !ok {
+		return obj
+	}
+	result := copyWithoutField(obj, "stringData")
+	data, _ := result["data"].(map[string]interface{})
+	if data == nil {
+		data = map[string]interface{}{}
+	}
+	for k, v := range stringData {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+	}
+	result["data"] = data
+	return result
+}
+
+func copyWithoutField(obj map[string]interface{}, fields ...string) map[string]interface{} {
+	result := runtime.DeepCopyJSON(obj)
+	unstructured.RemoveNestedField(result, fields...)
+	return result
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// startDriftSchedule checks the drift of the deployed releases of every cluster at the configured interval
+func startDriftSchedule() {
+	interval := config.Env.DriftCheckInterval
+	if interval <= 0 {
+		return
+	}
+	log.Infof("Drift check scheduled :: interval: %v", interval)
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for range ticker.C {
+			runScheduledDriftCheck()
+		}
+	}()
+}
+
+func runScheduledDriftCheck() {
+	claims := common.SystemClaims()
+	clusters, err := common.AccessibleClusters(claims)
+	if err != nil {
+		log.Errorf("runScheduledDriftCheck:: %v", err)
+		return
+	}
+
+	rlf := &releaseListFilter{Statuses: []string{release.StatusDeployed.String()}}
+	for _, clusterId := range clusters {
+		kubeInfo, err := common.InitClusterKubeInfo(context.Background(), claims, clusterId, "")
+		if err != nil {
+			log.Errorf("runScheduledDriftCheck:: cluster: %s :: %v", clusterId, err)
+			continue
+		}
+		actionConfig, err := common.KubeActionConfigInit(kubeInfo)
+		if err != nil {
+			log.Errorf("runScheduledDriftCheck:: cluster: %s :: %v", clusterId, err)
+			continue
+		}
+		releases, err := rlf.run(actionConfig)
+		if err != nil {
+			log.Errorf("runScheduledDriftCheck:: cluster: %s :: %v", clusterId, err)
+			continue
+		}
+		checked := map[string]bool{}
+		for _, rel := range releases {
+			checked[driftReportKey(clusterId, rel.Namespace, rel.Name)] = true
+			drift, err := checkReleaseDrift(actionConfig, clusterId, rel)
+			if err != nil {
+				continue
+			}
+			if drift.Drifted {
+				log.Warnf("Release drift detected :: cluster: %s, namespace: %s, name: %s", clusterId, rel.Namespace, rel.Name)
+			}
+		}
+
+		// the reports of releases no longer deployed are dropped
+		driftReports.Range(func(key, value interface{}) bool {
+			if value.(*releaseDrift).ClusterId == clusterId && !checked[key.(string)] {
+				driftReports.Delete(key)
+			}
+			return true
+		})
+	}
+}
@@ -0,0 +1,283 @@
+package handler
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"go-api/config"
+	"golang.org/x/crypto/openpgp/clearsign" //nolint
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/provenance"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	hostedChartFormFile = "chart"
+	hostedProvFormFile  = "prov"
+	hostedIndexFileName = "index.yaml"
+	chartArchiveExt     = ".tgz"
+	chartProvExt        = ".prov"
+)
+
+// hostedRepoMutex serializes the chart uploads and deletions with the index regeneration
+var hostedRepoMutex sync.Mutex
+
+// hostedRepoConflict disables the hosted repository when a configured repository already has its name
+var hostedRepoConflict bool
+
+type hostedChartElement struct {
+	Name       string `json:"name"`
+	Version    string `json:"version"`
+	AppVersion string `json:"app_version"`
+	Digest     string `json:"digest"`
+	Signed     bool   `json:"signed"`
+}
+
+// UploadHostedChart
+// @Summary Upload Chart to Hosted Repository
+// @Tags Hosted Repository
+// @Accept mpfd
+// @Produce json
+// @Router /api/hosted/charts [Post]
+func UploadHostedChart(c *fiber.Ctx) error {
+	if !hostedRepoEnabled() {
+		return common.RespErr(c, fmt.Errorf(common.HOSTED_REPO_DISABLED))
+	}
+	force, err := strconv.ParseBool(c.Query("force", "false"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chartHeader, err := c.FormFile(hostedChartFormFile)
+	if err != nil {
+		return common.RespErr(c, fmt.Errorf(common.CHART_PACKAGE_REQUIRED))
+	}
+	data, err := readFormFile(c, hostedChartFormFile)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.LoadArchive(bytes.NewReader(data))
+	if err != nil || chrt.Validate() != nil {
+		log.Errorf("UploadHostedChart:: invalid chart package (file: %s) :: %v", chartHeader.Filename, err)
+		return common.RespErr(c, fmt.Errorf(common.CHART_PACKAGE_INVALID))
+	}
+	digest, err := provenance.Digest(bytes.NewReader(data))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	// the provenance file has to be a signed message for this very archive
+	var prov []byte
+	if _, err := c.FormFile(hostedProvFormFile); err == nil {
+		prov, err = readFormFile(c, hostedProvFormFile)
+		if err != nil {
+			return common.RespErr(c, err)
+		}
+		block, _ := clearsign.Decode(prov)
+		if block == nil || !strings.Contains(string(block.Plaintext), "sha256:"+digest) {
+			return common.RespErr(c, fmt.Errorf(common.CHART_PROVENANCE_INVALID))
+		}
+	}
+
+	hostedRepoMutex.Lock()
+	defer hostedRepoMutex.Unlock()
+
+	archivePath := hostedChartPath(chrt.Name(), chrt.Metadata.Version)
+	if FileExists(archivePath) && !force {
+		return common.RespErr(c, fmt.Errorf(common.CHART_VERSION_ALREADY_EXISTS))
+	}
+
+	log.Infof("Upload hosted chart :: name: %s, version: %s", chrt.Name(), chrt.Metadata.Version)
+	if err := os.WriteFile(archivePath, data, 0644); err != nil {
+		return common.RespErr(c, err)
+	}
+	provPath := archivePath + chartProvExt
+	if prov != nil {
+		err = os.WriteFile(provPath, prov, 0644)
+	} else if FileExists(provPath) {
+		// a replaced archive must not keep the provenance of the previous one
+		err = RemoveFile(provPath)
+	}
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	if err := regenerateHostedIndex(); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	return common.RespOK(c, hostedChartElement{
+		Name:       chrt.Name(),
+		Version:    chrt.Metadata.Version,
+		AppVersion: chrt.Metadata.AppVersion,
+		Digest:     digest,
+		Signed:     prov != nil,
+	})
+}
+
+// DeleteHostedChartVersion
+// @Summary Delete Chart Version from Hosted Repository
+// @Tags Hosted Repository
+// @Accept json
+// @Produce json
+// @Router /api/hosted/charts/:charts/versions/:version [Delete]
+func DeleteHostedChartVersion(c *fiber.Ctx) error {
+	if !hostedRepoEnabled() {
+		return common.RespErr(c, fmt.Errorf(common.HOSTED_REPO_DISABLED))
+	}
+
+	hostedRepoMutex.Lock()
+	defer hostedRepoMutex.Unlock()
+
+	archivePath := hostedChartPath(c.Params("charts"), c.Params("version"))
+	if !FileExists(archivePath) {
+		return common.RespErr(c, fmt.Errorf(common.CHART_VERSION_NOT_FOUND))
+	}
+
+	log.Infof("Delete hosted chart :: name: %s, version: %s", c.Params("charts"), c.Params("version"))
+	if err := RemoveFile(archivePath); err != nil {
+		return common.RespErr(c, err)
+	}
+	if FileExists(archivePath + chartProvExt) {
+		if err := RemoveFile(archivePath + chartProvExt); err != nil {
+			return common.RespErr(c, err)
+		}
+	}
+
+	if err := regenerateHostedIndex(); err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, nil)
+}
+
+// ServeHostedRepo
+// @Summary Serve Hosted Repository Files
+// @Tags Hosted Repository
+// @Produce octet-stream
+// @Router /charts/:file [Get]
+func ServeHostedRepo(c *fiber.Ctx) error {
+	if !hostedRepoEnabled() {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	name := filepath.Base(c.Params("file"))
+	switch {
+	case name == hostedIndexFileName:
+		c.Set(fiber.HeaderContentType, chartFileContentTypes[".yaml"])
+	case strings.HasSuffix(name, chartArchiveExt):
+		c.Set(fiber.HeaderContentType, chartFileContentTypes[chartArchiveExt])
+	case strings.HasSuffix(name, chartProvExt):
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+	default:
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+
+	// files are streamed instead of c.SendFile, whose file cache would serve a stale index.yaml
+	f, err := os.Open(filepath.Join(config.Env.HostedRepoDir, name))
+	if err != nil {
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return c.SendStatus(fiber.StatusNotFound)
+	}
+	return c.SendStream(f, int(info.Size()))
+}
+
+// ensureHostedRepo indexes the hosted repository and registers it in the repositories file,
+// so it is listed and searched like any other repository.
+// A repository configured with the same name is kept and the hosted repository is not started
+func ensureHostedRepo() {
+	if !hostedRepoEnabled() {
+		return
+	}
+
+	if err := syncRepoLock(settings.RepositoryConfig); err != nil {
+		log.Errorf("ensureHostedRepo:: %v", err)
+		return
+	}
+	f, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		log.Errorf("ensureHostedRepo:: faild load file :: %v", err)
+		return
+	}
+	entry := f.Get(config.Env.HostedRepoName)
+	if entry != nil && entry.URL != config.Env.HostedRepoURL {
+		hostedRepoConflict = true
+		log.Errorf("ensureHostedRepo:: repository %s (url: %s) already exists, the hosted repository is not started. Set a different HOSTED_REPO_NAME",
+			entry.Name, entry.URL)
+		return
+	}
+
+	hostedRepoMutex.Lock()
+	err = regenerateHostedIndex()
+	hostedRepoMutex.Unlock()
+	if err != nil {
+		log.Errorf("ensureHostedRepo:: failed to index the hosted repo :: %v", err)
+		return
+	}
+
+	if entry != nil {
+		return
+	}
+	f.Update(&repo.Entry{Name: config.Env.HostedRepoName, URL: config.Env.HostedRepoURL})
+	if err := f.WriteFile(settings.RepositoryConfig, 0600); err != nil {
+		log.Errorf("ensureHostedRepo:: Write Repofile :: %v", err)
+	}
+}
+
+// regenerateHostedIndex rebuilds the index.yaml of the hosted repository and refreshes its cached copy,
+// the caller must hold hostedRepoMutex
+func regenerateHostedIndex() error {
+	index, err := repo.IndexDirectory(config.Env.HostedRepoDir, "")
+	if err != nil {
+		log.Errorf("regenerateHostedIndex:: %v", err)
+		return err
+	}
+	index.SortEntries()
+
+	if err := index.WriteFile(filepath.Join(config.Env.HostedRepoDir, hostedIndexFileName), 0644); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(settings.RepositoryCache, os.ModePerm); err != nil {
+		return err
+	}
+	defer indexCache.invalidate(config.Env.HostedRepoName)
+	return index.WriteFile(filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(config.Env.HostedRepoName)), 0644)
+}
+
+func hostedRepoEnabled() bool {
+	return config.Env.HostedRepoDir != "" && config.Env.HostedRepoName != "" && !hostedRepoConflict
+}
+
+func isHostedRepo(repoName string) bool {
+	return hostedRepoEnabled() && repoName == config.Env.HostedRepoName
+}
+
+func hostedChartPath(name string, version string) string {
+	return filepath.Join(config.Env.HostedRepoDir, filepath.Base(fmt.Sprintf("%s-%s%s", name, version, chartArchiveExt)))
+}
+
+func readFormFile(c *fiber.Ctx, key string) ([]byte, error) {
+	fileHeader, err := c.FormFile(key)
+	if err != nil {
+		return nil, err
+	}
+	f, err := fileHeader.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return io.ReadAll(f)
+}
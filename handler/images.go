@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sort"
+	"strings"
+)
+
+// pod spec location of each workload kind
+var workloadPodSpecPaths = map[string][]string{
+	"Pod":                   {"spec"},
+	"Deployment":            {"spec", "template", "spec"},
+	"ReplicaSet":            {"spec", "template", "spec"},
+	"ReplicationController": {"spec", "template", "spec"},
+	"StatefulSet":           {"spec", "template", "spec"},
+	"DaemonSet":             {"spec", "template", "spec"},
+	"Job":                   {"spec", "template", "spec"},
+	"CronJob":               {"spec", "jobTemplate", "spec", "template", "spec"},
+}
+
+// container lists of a pod spec and the type they are reported as
+var podContainerFields = []struct {
+	Field string
+	Type  string
+}{
+	{"initContainers", "init"},
+	{"containers", "container"},
+	{"ephemeralContainers", "ephemeral"},
+}
+
+type imagesResult struct {
+	Images    []string         `json:"images"`
+	Workloads []workloadImages `json:"workloads"`
+}
+
+type workloadImages struct {
+	Kind       string           `json:"kind"`
+	Name       string           `json:"name"`
+	Namespace  string           `json:"namespace"`
+	Containers []containerImage `json:"containers"`
+}
+
+type containerImage struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Image string `json:"image"`
+}
+
+// GetChartImages
+// @Summary Get Chart Images
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/images [Post]
+func GetChartImages(c *fiber.Ctx) error {
+	element := new(templateElement)
+	if err := c.BodyParser(element); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	rel, err := renderChart(chrt, element)
+	if err != nil {
+		return respValuesErr(c, err)
+	}
+
+	result, err := extractReleaseImages(rel)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, result)
+}
+
+// GetReleaseImages
+// @Summary Get Release Images
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/images [Get]
+func GetReleaseImages(c *fiber.Ctx) error {
+	name := c.Params("release")
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	client := action.NewGet(actionConfig)
+	rel, err := client.Run(name)
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, err)
+	}
+
+	result, err := extractReleaseImages(rel)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, result)
+}
+
+// extractReleaseImages collects the container images of the workloads in the release manifest and hooks
+func extractReleaseImages(rel *release.Release) (*imagesResult, error) {
+	manifests := []string{rel.Manifest}
+	for _, h := range rel.Hooks {
+		manifests = append(manifests, h.Manifest)
+	}
+
+	objs, err := ParseManifestObjects(strings.Join(manifests, "\n---\n"))
+	if err != nil {
+		return nil, err
+	}
+
+	result := &imagesResult{
+		Images:    make([]string, 0),
+		Workloads: make([]workloadImages, 0),
+	}
+	seen := map[string]bool{}
+	for _, obj := range objs {
+		workload, ok := workloadContainerImages(obj)
+		if !ok {
+			continue
+		}
+		for _, container := range workload.Containers {
+			if !seen[container.Image] {
+				seen[container.Image] = true
+				result.Images = append(result.Images, container.Image)
+			}
+		}
+		result.Workloads = append(result.Workloads, workload)
+	}
+	sort.Strings(result.Images)
+	return result, nil
+}
+
+func workloadContainerImages(obj *unstructured.Unstructured) (workloadImages, bool) {
+	path, ok := workloadPodSpecPaths[obj.GetKind()]
+	if !ok {
+		return workloadImages{}, false
+	}
+	podSpec, found, err := unstructured.NestedMap(obj.Object, path...)
+	if err != nil || !found {
+		return workloadImages{}, false
+	}
+
+	workload := workloadImages{
+		Kind:       obj.GetKind(),
+		Name:       obj.GetName(),
+		Namespace:  obj.GetNamespace(),
+		Containers: make([]containerImage, 0),
+	}
+	seen := map[string]bool{}
+	for _, f := range podContainerFields {
+		containers, _, _ := unstructured.NestedSlice(podSpec, f.Field)
+		for _, container := range containers {
+			container, ok := container.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			image, _, _ := unstructured.NestedString(container, "image")
+			name, _, _ := unstructured.NestedString(container, "name")
+			if image == "" || seen[f.Type+"/"+image] {
+				continue
+			}
+			seen[f.Type+"/"+image] = true
+			workload.Containers = append(workload.Containers, containerImage{Name: name, Type: f.Type, Image: image})
+		}
+	}
+	return workload, len(workload.Containers) > 0
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/cmd/helm/search"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// indexCache keeps the parsed repository index files and their search indexes in memory,
+// they are rebuilt only when a repository is added, removed or updated
+var indexCache = &searchIndexCache{repos: map[string]*cachedRepoIndex{}}
+
+type searchIndexCache struct {
+	mu     sync.Mutex
+	repos  map[string]*cachedRepoIndex
+	all    *search.Index
+	allKey string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+}
+
+type cachedRepoIndex struct {
+	modTime   time.Time
+	size      int64
+	indexFile *repo.IndexFile
+	index     *search.Index
+}
+
+type indexCacheStats struct {
+	Repositories int     `json:"repositories"`
+	Hits         int64   `json:"hits"`
+	Misses       int64   `json:"misses"`
+	HitRatio     float64 `json:"hit_ratio"`
+}
+
+// GetIndexCacheStats
+// @Summary Get Search Index Cache Stats
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/cache/stats [Get]
+func GetIndexCacheStats(c *fiber.Ctx) error {
+	return common.RespOK(c, indexCache.stats())
+}
+
+// repoIndex returns the search index of a repository, loading its cached index file only when it changed
+func (s *searchIndexCache) repoIndex(repoName string) (*search.Index, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.load(repoName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.index, nil
+}
+
+// repoIndexFile returns the cached index file of a repository, it is shared so it must not be changed
+func (s *searchIndexCache) repoIndexFile(repoName string) (*repo.IndexFile, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, err := s.load(repoName)
+	if err != nil {
+		return nil, err
+	}
+	return entry.indexFile, nil
+}
+
+// allIndex returns the search index of all the repositories,
+// rebuilt when the repositories or any of their index files changed
+func (s *searchIndexCache) allIndex(repoNames []string) *search.Index {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := make(map[string]*cachedRepoIndex, len(repoNames))
+	keys := make([]string, 0, len(repoNames))
+	for _, repoName := range repoNames {
+		entry, err := s.load(repoName)
+		if err != nil {
+			continue
+		}
+		entries[repoName] = entry
+		// the same modification time and size that tell a changed index file of a repository
+		keys = append(keys, fmt.Sprintf("%s@%d:%d", repoName, entry.modTime.UnixNano(), entry.size))
+	}
+
+	allKey := strings.Join(keys, ",")
+	if s.all != nil && s.allKey == allKey {
+		return s.all
+	}
+
+	index := search.NewIndex()
+	for _, repoName := range repoNames {
+		if entry, ok := entries[repoName]; ok {
+			index.AddRepo(repoName, entry.indexFile, true)
+		}
+	}
+	s.all, s.allKey = index, allKey
+	return index
+}
+
+// load returns the cached index of a repository, the caller must hold the lock
+func (s *searchIndexCache) load(repoName string) (*cachedRepoIndex, error) {
+	path := filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(repoName))
+	info, err := os.Stat(path)
+	if err != nil {
+		delete(s.repos, repoName)
+		return nil, fmt.Errorf(common.REPO_CORRUPT_MISSING)
+	}
+
+	// the index file is also checked for changes made outside of the api
+	if entry, ok := s.repos[repoName]; ok && entry.modTime.Equal(info.ModTime()) && entry.size == info.Size() {
+		s.hits.Add(1)
+		return entry, nil
+	}
+
+	s.misses.Add(1)
+	indexFile, err := repo.LoadIndexFile(path)
+	if err != nil {
+		delete(s.repos, repoName)
+		return nil, fmt.Errorf(common.REPO_CORRUPT_MISSING)
+	}
+	index := search.NewIndex()
+	index.AddRepo(repoName, indexFile, true)
+
+	entry := &cachedRepoIndex{modTime: info.ModTime(), size: info.Size(), indexFile: indexFile, index: index}
+	s.repos[repoName] = entry
+	return entry, nil
+}
+
+// invalidate drops the cached index of the repository, and of all repositories without a name
+func (s *searchIndexCache) invalidate(repoName string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	log.Debugf("Invalidate search index cache :: repo: %s", repoName)
+	if repoName == "" {
+		s.repos = map[string]*cachedRepoIndex{}
+	} else {
+		delete(s.repos, repoName)
+	}
+	s.all, s.allKey = nil, ""
+}
+
+func (s *searchIndexCache) stats() indexCacheStats {
+	s.mu.Lock()
+	repositories := len(s.repos)
+	s.mu.Unlock()
+
+	stats := indexCacheStats{
+		Repositories: repositories,
+		Hits:         s.hits.Load(),
+		Misses:       s.misses.Load(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
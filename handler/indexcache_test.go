@@ -0,0 +1,76 @@
+package handler
+
+import (
+	"fmt"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const (
+	benchRepoName      = "bench"
+	benchChartCount    = 1000
+	benchVersionsCount = 10
+)
+
+// writeBenchIndex writes a repository index file of the size of a large public repository to the repository cache
+func writeBenchIndex(b *testing.B) {
+	b.Helper()
+	settings.RepositoryCache = b.TempDir()
+
+	index := repo.NewIndexFile()
+	for i := 0; i < benchChartCount; i++ {
+		for v := 0; v < benchVersionsCount; v++ {
+			metadata := &chart.Metadata{
+				APIVersion:  chart.APIVersionV2,
+				Name:        fmt.Sprintf("chart-%04d", i),
+				Version:     fmt.Sprintf("1.%d.0", v),
+				AppVersion:  fmt.Sprintf("2.%d.0", v),
+				Description: fmt.Sprintf("A Helm chart for the service %d of the benchmark repository", i),
+				Keywords:    []string{"benchmark", fmt.Sprintf("service-%d", i)},
+			}
+			url := fmt.Sprintf("%s-%s.tgz", metadata.Name, metadata.Version)
+			if err := index.MustAdd(metadata, url, "https://charts.example.com", "sha256:0"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+	index.Generated = time.Now()
+	if err := index.WriteFile(filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile(benchRepoName)), 0644); err != nil {
+		b.Fatal(err)
+	}
+}
+
+// BenchmarkSearchIndexCache searches a repository with the index file parsed on every search (cold),
+// as before the cache, and with the cached search index (warm)
+func BenchmarkSearchIndexCache(b *testing.B) {
+	writeBenchIndex(b)
+
+	b.Run("cold", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			indexCache.invalidate(benchRepoName)
+			index, err := buildSearchIndex(benchRepoName)
+			if err != nil {
+				b.Fatal(err)
+			}
+			index.SearchLiteral("service", searchMaxScore)
+		}
+	})
+
+	b.Run("warm", func(b *testing.B) {
+		if _, err := buildSearchIndex(benchRepoName); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			index, err := buildSearchIndex(benchRepoName)
+			if err != nil {
+				b.Fatal(err)
+			}
+			index.SearchLiteral("service", searchMaxScore)
+		}
+	})
+}
@@ -0,0 +1,162 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/golang-jwt/jwt/v5"
+	"go-api/common"
+	"go-api/config"
+	"helm.sh/helm/v3/pkg/release"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultInventoryConcurrency = 8
+	defaultInventoryTimeout     = 30 * time.Second
+)
+
+type inventoryReleaseElement struct {
+	ClusterId string `json:"clusterId"`
+	releaseElement
+}
+
+type clusterError struct {
+	ClusterId     string `json:"clusterId"`
+	ResultCode    string `json:"resultCode"`
+	DetailMessage string `json:"detailMessage"`
+}
+
+type clusterReleases struct {
+	clusterId string
+	releases  []*release.Release
+	err       error
+}
+
+// ListReleaseInventory
+// @Summary List Releases of All Clusters
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/releases [Get]
+func ListReleaseInventory(c *fiber.Ctx) error {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	rlf, err := releaseListFilterCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	if len(lse.Sort) == 0 {
+		lse.Sort, _ = parseListSort("-updated,clusterId,namespace,name")
+	}
+	namespace := c.Query("namespace")
+	if strings.ToLower(namespace) == common.ALL_NAMESPACE {
+		namespace = ""
+	}
+
+	claims := common.UserClaims(c)
+	clusters, err := common.AccessibleClusters(claims)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	sort.Strings(clusters)
+
+	elements := make([]interface{}, 0)
+	errs := make([]clusterError, 0)
+	for _, result := range listClustersReleases(claims, clusters, namespace, rlf) {
+		if result.err != nil {
+			errs = append(errs, clusterError{
+				ClusterId:     result.clusterId,
+				ResultCode:    result.err.Error(),
+				DetailMessage: common.Localize(c, result.err.Error()),
+			})
+			continue
+		}
+		for _, r := range result.releases {
+			elements = append(elements, inventoryReleaseElement{
+				ClusterId:      result.clusterId,
+				releaseElement: constructReleaseElement(r, false),
+			})
+		}
+	}
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.PartialListRespOK(c, itemCount, resultData, errs)
+}
+
+// listClustersReleases lists the releases of the clusters with a bounded number of clusters at once,
+// a cluster that does not answer in time is reported with a timeout and does not hold the others.
+// The deadline is passed to the vault and kube requests so the work of a timed out cluster stops,
+// and its slot is only released then so the concurrency bounds the running work
+func listClustersReleases(claims jwt.MapClaims, clusters []string, namespace string, rlf *releaseListFilter) []clusterReleases {
+	concurrency := config.Env.InventoryConcurrency
+	if concurrency <= 0 {
+		concurrency = defaultInventoryConcurrency
+	}
+	timeout := config.Env.InventoryClusterTimeout
+	if timeout <= 0 {
+		timeout = defaultInventoryTimeout
+	}
+
+	results := make([]clusterReleases, len(clusters))
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, clusterId := range clusters {
+		wg.Add(1)
+		sem <- struct{}{}
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
+		// buffered, so a cluster answering after the timeout does not block its goroutine
+		done := make(chan clusterReleases, 1)
+		go func(clusterId string) {
+			defer func() {
+				<-sem
+				// malformed cluster info must not take down the server outside of the request goroutine
+				if r := recover(); r != nil {
+					log.Errorf("listClustersReleases:: cluster: %s :: %v", clusterId, r)
+					done <- clusterReleases{clusterId: clusterId, err: fmt.Errorf(common.FAILED_TO_READ_CLUSTER_INFO)}
+				}
+			}()
+			releases, err := listClusterReleases(ctx, claims, clusterId, namespace, rlf)
+			done <- clusterReleases{clusterId: clusterId, releases: releases, err: err}
+		}(clusterId)
+
+		go func(i int, clusterId string) {
+			defer func() {
+				cancel()
+				wg.Done()
+			}()
+			select {
+			case results[i] = <-done:
+			case <-ctx.Done():
+				log.Errorf("listClustersReleases:: cluster: %s :: no response in %v", clusterId, timeout)
+				results[i] = clusterReleases{clusterId: clusterId, err: fmt.Errorf(common.CLUSTER_REQUEST_TIMEOUT)}
+			}
+		}(i, clusterId)
+	}
+	wg.Wait()
+	return results
+}
+
+func listClusterReleases(ctx context.Context, claims jwt.MapClaims, clusterId string, namespace string, rlf *releaseListFilter) ([]*release.Release, error) {
+	kubeInfo, err := common.InitClusterKubeInfo(ctx, claims, clusterId, namespace)
+	if err != nil {
+		return nil, err
+	}
+	actionConfig, err := common.KubeActionConfigInit(kubeInfo)
+	if err != nil {
+		return nil, err
+	}
+	releases, err := rlf.run(actionConfig)
+	if err != nil {
+		log.Errorf("listClusterReleases:: cluster: %s :: %v", clusterId, err)
+		return nil, err
+	}
+	return releases, nil
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/lint/support"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+)
+
+// lintChartFormFile is the multipart form field of an uploaded chart archive
+const lintChartFormFile = "chart"
+
+var lintSeverities = map[int]string{
+	support.UnknownSev: "UNKNOWN",
+	support.InfoSev:    "INFO",
+	support.WarningSev: "WARNING",
+	support.ErrorSev:   "ERROR",
+}
+
+var (
+	// template: mychart/templates/deployment.yaml:12:3: executing ...
+	lintTemplatePosition = regexp.MustCompile(`template: [^/\s]+/(\S+?):(\d+)(?::\d+)?:`)
+	// yaml: line 5: did not find expected key
+	lintYamlLine = regexp.MustCompile(`line (\d+)`)
+)
+
+type lintElement struct {
+	Values    string `json:"values" form:"values"`
+	Namespace string `json:"namespace" form:"namespace"`
+	Strict    bool   `json:"strict" form:"strict"`
+}
+
+type lintResult struct {
+	Passed   bool          `json:"passed"`
+	Messages []lintMessage `json:"messages"`
+}
+
+type lintMessage struct {
+	Severity string `json:"severity"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Message  string `json:"message"`
+}
+
+// LintRepoChart
+// @Summary Lint Repository Chart
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/lint [Post]
+func LintRepoChart(c *fiber.Ctx) error {
+	element := new(lintElement)
+	if err := c.BodyParser(element); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	result, err := lintChart(cp, element)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, result)
+}
+
+// LintUploadedChart
+// @Summary Lint Uploaded Chart
+// @Tags Charts
+// @Accept mpfd
+// @Produce json
+// @Router /api/charts/lint [Post]
+func LintUploadedChart(c *fiber.Ctx) error {
+	element := new(lintElement)
+	if err := c.BodyParser(element); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	fileHeader, err := c.FormFile(lintChartFormFile)
+	if err != nil {
+		return common.RespErr(c, fmt.Errorf(common.CHART_PACKAGE_REQUIRED))
+	}
+
+	tempDir, err := os.MkdirTemp("", "catalog-lint")
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	// the archive extension tells helm to extract it before linting
+	cp := filepath.Join(tempDir, "chart.tgz")
+	if err := c.SaveFile(fileHeader, cp); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	result, err := lintChart(cp, element)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, result)
+}
+
+// lintChart runs the helm lint rules on a chart archive with the given values
+func lintChart(cp string, element *lintElement) (*lintResult, error) {
+	vals, err := mergeValues(element.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	client := action.NewLint()
+	client.Strict = element.Strict
+	client.Namespace = procTemplateDefault(element.Namespace, defaultTemplateNamespace)
+	lr := client.Run([]string{cp}, vals)
+
+	result := &lintResult{
+		Passed:   len(lr.Errors) == 0,
+		Messages: make([]lintMessage, 0, len(lr.Messages)),
+	}
+	if lr.TotalChartsLinted == 0 {
+		// the chart could not be opened at all
+		for _, e := range lr.Errors {
+			log.Errorf("lintChart:: %v", e)
+			result.Messages = append(result.Messages, lintMessage{
+				Severity: lintSeverities[support.ErrorSev],
+				Message:  e.Error(),
+			})
+		}
+		return result, nil
+	}
+
+	for _, m := range lr.Messages {
+		message := lintMessage{
+			Severity: lintSeverities[m.Severity],
+			File:     m.Path,
+			Message:  m.Err.Error(),
+		}
+		if match := lintTemplatePosition.FindStringSubmatch(message.Message); match != nil {
+			message.File = match[1]
+			message.Line, _ = strconv.Atoi(match[2])
+		} else if match := lintYamlLine.FindStringSubmatch(message.Message); match != nil {
+			message.Line, _ = strconv.Atoi(match[1])
+		}
+		result.Messages = append(result.Messages, message)
+	}
+	return result, nil
+}
@@ -0,0 +1,266 @@
+package handler
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"hash/fnv"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// list field names are the json names of the items, nested fields are joined with a dot
+var listFieldPattern = regexp.MustCompile(`^[A-Za-z0-9_]+(\.[A-Za-z0-9_]+)*$`)
+
+type ListSortField struct {
+	Field string
+	Desc  bool
+}
+
+type ListFilter struct {
+	Field  string
+	Values []string
+}
+
+type listContinueToken struct {
+	Start int    `json:"start"`
+	Query uint32 `json:"query"`
+}
+
+// parseListSort parses sort=field,-field, a leading - sorts the field in descending order
+func parseListSort(value string) ([]ListSortField, error) {
+	var fields []ListSortField
+	for _, f := range splitListParam(value, ",") {
+		field := ListSortField{Field: strings.TrimPrefix(f, "-"), Desc: strings.HasPrefix(f, "-")}
+		if !listFieldPattern.MatchString(field.Field) {
+			return nil, fmt.Errorf(common.SORT_VAL_INVALID)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// parseListFilter parses filter=field:value|value,field:value, items match any value of every field
+func parseListFilter(value string) ([]ListFilter, error) {
+	var filters []ListFilter
+	for _, f := range splitListParam(value, ",") {
+		field, values, ok := strings.Cut(f, ":")
+		field = strings.TrimSpace(field)
+		if !ok || !listFieldPattern.MatchString(field) {
+			return nil, fmt.Errorf(common.FILTER_VAL_INVALID)
+		}
+		filters = append(filters, ListFilter{Field: field, Values: splitListParam(values, "|")})
+	}
+	return filters, nil
+}
+
+// parseListFields parses fields=field,field for the projection of the items
+func parseListFields(value string) ([]string, error) {
+	fields := splitListParam(value, ",")
+	for _, f := range fields {
+		if !listFieldPattern.MatchString(f) {
+			return nil, fmt.Errorf(common.FIELDS_VAL_INVALID)
+		}
+	}
+	return fields, nil
+}
+
+func splitListParam(value string, sep string) []string {
+	var parts []string
+	for _, p := range strings.Split(value, sep) {
+		if p = strings.TrimSpace(p); p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return parts
+}
+
+// filterSortResourceList applies the search keyword, the filters and the sort fields to the list
+func filterSortResourceList(list []interface{}, lse *ListSearchElement) []interface{} {
+	if lse.SearchName != "" {
+		list = searchResourceName(list, lse.SearchName)
+	}
+	if len(lse.Filters) == 0 && len(lse.Sort) == 0 {
+		return list
+	}
+
+	fieldMaps := make(map[int]map[string]interface{}, len(list))
+	indexes := make([]int, 0, len(list))
+	for i, item := range list {
+		fieldMaps[i] = listItemFields(item)
+		if matchListFilters(fieldMaps[i], lse.Filters) {
+			indexes = append(indexes, i)
+		}
+	}
+
+	sort.SliceStable(indexes, func(a, b int) bool {
+		for _, sf := range lse.Sort {
+			cmp := compareListValues(listFieldValue(fieldMaps[indexes[a]], sf.Field), listFieldValue(fieldMaps[indexes[b]], sf.Field))
+			if cmp == 0 {
+				continue
+			}
+			if sf.Desc {
+				return cmp > 0
+			}
+			return cmp < 0
+		}
+		return false
+	})
+
+	result := make([]interface{}, 0, len(indexes))
+	for _, i := range indexes {
+		result = append(result, list[i])
+	}
+	return result
+}
+
+// projectResourceList keeps only the selected fields of the items
+func projectResourceList(list []interface{}, lse *ListSearchElement) []interface{} {
+	if len(lse.Fields) == 0 {
+		return list
+	}
+	projected := make([]interface{}, 0, len(list))
+	for _, item := range list {
+		fields := listItemFields(item)
+		p := map[string]interface{}{}
+		for _, f := range lse.Fields {
+			if value := listFieldValue(fields, f); value != nil {
+				setListFieldValue(p, f, value)
+			}
+		}
+		projected = append(projected, p)
+	}
+	return projected
+}
+
+// listPageStart returns where the requested page starts, from the continue token or the offset
+func listPageStart(lse *ListSearchElement) (int, error) {
+	if lse.Continue == "" {
+		return lse.Offset * lse.Limit, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(lse.Continue)
+	if err != nil {
+		return 0, fmt.Errorf(common.CONTINUE_VAL_INVALID)
+	}
+	var token listContinueToken
+	if err := json.Unmarshal(data, &token); err != nil || token.Start < 0 || token.Query != lse.query {
+		return 0, fmt.Errorf(common.CONTINUE_VAL_INVALID)
+	}
+	return token.Start, nil
+}
+
+// listContinue returns the opaque token of the page starting at start
+func listContinue(lse *ListSearchElement, start int) string {
+	data, _ := json.Marshal(listContinueToken{Start: start, Query: lse.query})
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// listQueryHash binds a continue token to the query parameters it was issued for,
+// except the paging and field selection parameters
+func listQueryHash(c *fiber.Ctx) uint32 {
+	var params []string
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		switch string(key) {
+		case "continue", "offset", "fields":
+		default:
+			params = append(params, string(key)+"="+string(value))
+		}
+	})
+	sort.Strings(params)
+
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(strings.Join(params, "&")))
+	return h.Sum32()
+}
+
+func listItemFields(item interface{}) map[string]interface{} {
+	fields := map[string]interface{}{}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return fields
+	}
+	_ = json.Unmarshal(data, &fields)
+	return fields
+}
+
+func listFieldValue(fields map[string]interface{}, path string) interface{} {
+	var value interface{} = fields
+	for _, key := range strings.Split(path, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if value, ok = m[key]; !ok {
+			return nil
+		}
+	}
+	return value
+}
+
+func setListFieldValue(fields map[string]interface{}, path string, value interface{}) {
+	keys := strings.Split(path, ".")
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := fields[key].(map[string]interface{})
+		if !ok {
+			next = map[string]interface{}{}
+			fields[key] = next
+		}
+		fields = next
+	}
+	fields[keys[len(keys)-1]] = value
+}
+
+func matchListFilters(fields map[string]interface{}, filters []ListFilter) bool {
+	for _, f := range filters {
+		value := fmt.Sprint(listFieldValue(fields, f.Field))
+		matched := false
+		for _, v := range f.Values {
+			if strings.EqualFold(value, v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// compareListValues orders numbers, booleans and strings by their kind, missing values first
+func compareListValues(a interface{}, b interface{}) int {
+	switch av := a.(type) {
+	case nil:
+		if b == nil {
+			return 0
+		}
+		return -1
+	case float64:
+		if bv, ok := b.(float64); ok {
+			switch {
+			case av < bv:
+				return -1
+			case av > bv:
+				return 1
+			}
+			return 0
+		}
+	case bool:
+		if bv, ok := b.(bool); ok {
+			switch {
+			case av == bv:
+				return 0
+			case !av:
+				return -1
+			}
+			return 1
+		}
+	}
+	if b == nil {
+		return 1
+	}
+	return strings.Compare(strings.ToLower(fmt.Sprint(a)), strings.ToLower(fmt.Sprint(b)))
+}
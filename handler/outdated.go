@@ -0,0 +1,256 @@
+package handler
+
+import (
+	"github.com/Masterminds/semver/v3"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/cmd/helm/search"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/repo"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	versionDistanceMajor = "major"
+	versionDistanceMinor = "minor"
+	versionDistancePatch = "patch"
+	versionDistanceNone  = "none"
+)
+
+type outdatedReleaseElement struct {
+	ClusterId        string `json:"clusterId,omitempty"`
+	Name             string `json:"name"`
+	Namespace        string `json:"namespace"`
+	Status           string `json:"status"`
+	Chart            string `json:"chart"`
+	ChartVersion     string `json:"chart_version"`
+	AppVersion       string `json:"app_version"`
+	RepoName         string `json:"repoName"`
+	LatestVersion    string `json:"latest_version"`
+	LatestAppVersion string `json:"latest_app_version"`
+	Distance         string `json:"distance"`
+	Deprecated       bool   `json:"deprecated"`
+}
+
+// repoChartVersion is a chart version of the repository index with its parsed version
+type repoChartVersion struct {
+	repoName string
+	version  *semver.Version
+	chart    *repo.ChartVersion
+}
+
+// chartVersionIndex holds the versions of every chart of the repositories by chart name, newest first
+type chartVersionIndex map[string][]repoChartVersion
+
+// ListOutdatedReleases
+// @Summary List Outdated Releases
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/outdated [Get]
+func ListOutdatedReleases(c *fiber.Ctx) error {
+	lse, rlf, versions, err := outdatedReleasesCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	results, err := rlf.run(actionConfig)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	elements := make([]interface{}, 0)
+	for _, r := range results {
+		if element, ok := versions.outdatedRelease(r); ok {
+			elements = append(elements, element)
+		}
+	}
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.ListRespOK(c, itemCount, resultData)
+}
+
+// ListOutdatedReleaseInventory
+// @Summary List Outdated Releases of All Clusters
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/releases/outdated [Get]
+func ListOutdatedReleaseInventory(c *fiber.Ctx) error {
+	lse, rlf, versions, err := outdatedReleasesCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	namespace := c.Query("namespace")
+	if strings.ToLower(namespace) == common.ALL_NAMESPACE {
+		namespace = ""
+	}
+
+	claims := common.UserClaims(c)
+	clusters, err := common.AccessibleClusters(claims)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	sort.Strings(clusters)
+
+	elements := make([]interface{}, 0)
+	errs := make([]clusterError, 0)
+	for _, result := range listClustersReleases(claims, clusters, namespace, rlf) {
+		if result.err != nil {
+			errs = append(errs, clusterError{
+				ClusterId:     result.clusterId,
+				ResultCode:    result.err.Error(),
+				DetailMessage: common.Localize(c, result.err.Error()),
+			})
+			continue
+		}
+		for _, r := range result.releases {
+			if element, ok := versions.outdatedRelease(r); ok {
+				element.ClusterId = result.clusterId
+				elements = append(elements, element)
+			}
+		}
+	}
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.PartialListRespOK(c, itemCount, resultData, errs)
+}
+
+// outdatedReleasesCheck parses the list and release filters, only the deployed releases are checked
+// without a status, and repo=name limits the repositories the chart versions are looked up in
+func outdatedReleasesCheck(c *fiber.Ctx) (*ListSearchElement, *releaseListFilter, chartVersionIndex, error) {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	rlf, err := releaseListFilterCheck(c)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if len(rlf.Statuses) == 0 {
+		rlf.Statuses = []string{release.StatusDeployed.String()}
+	}
+	if len(lse.Sort) == 0 {
+		lse.Sort, _ = parseListSort("clusterId,namespace,name")
+	}
+
+	index, err := buildSearchIndexAll()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return lse, rlf, newChartVersionIndex(index, splitListParam(c.Query("repo"), ",")), nil
+}
+
+func newChartVersionIndex(index *search.Index, repoNames []string) chartVersionIndex {
+	versions := chartVersionIndex{}
+	for _, r := range index.All() {
+		repoName, _, _ := strings.Cut(r.Name, "/")
+		if len(repoNames) > 0 && !slices.Contains(repoNames, repoName) {
+			continue
+		}
+		v, err := semver.NewVersion(r.Chart.Version)
+		if err != nil {
+			continue
+		}
+		versions[r.Chart.Name] = append(versions[r.Chart.Name], repoChartVersion{repoName: repoName, version: v, chart: r.Chart})
+	}
+	for _, list := range versions {
+		sort.SliceStable(list, func(i, j int) bool {
+			if !list[i].version.Equal(list[j].version) {
+				return list[i].version.GreaterThan(list[j].version)
+			}
+			return list[i].repoName < list[j].repoName
+		})
+	}
+	return versions
+}
+
+// outdatedRelease reports the release when a newer version of its chart is available or its version is deprecated,
+// prereleases are only offered to releases already running a prerelease
+func (idx chartVersionIndex) outdatedRelease(r *release.Release) (outdatedReleaseElement, bool) {
+	if r.Chart == nil || r.Chart.Metadata == nil {
+		return outdatedReleaseElement{}, false
+	}
+	current, err := semver.NewVersion(r.Chart.Metadata.Version)
+	if err != nil {
+		return outdatedReleaseElement{}, false
+	}
+	versions := idx.releaseChartVersions(r)
+	if len(versions) == 0 {
+		return outdatedReleaseElement{}, false
+	}
+
+	var latest *repoChartVersion
+	deprecated := false
+	for i, v := range versions {
+		if latest == nil && (v.version.Prerelease() == "" || current.Prerelease() != "") {
+			latest = &versions[i]
+		}
+		if v.version.Equal(current) && v.chart.Deprecated {
+			deprecated = true
+		}
+	}
+	if latest == nil {
+		return outdatedReleaseElement{}, false
+	}
+
+	distance := versionDistance(current, latest.version)
+	if distance == versionDistanceNone && !deprecated {
+		return outdatedReleaseElement{}, false
+	}
+
+	element := constructReleaseElement(r, false)
+	return outdatedReleaseElement{
+		Name:             element.Name,
+		Namespace:        element.Namespace,
+		Status:           element.Status,
+		Chart:            element.Chart,
+		ChartVersion:     element.ChartVersion,
+		AppVersion:       element.AppVersion,
+		RepoName:         latest.repoName,
+		LatestVersion:    latest.chart.Version,
+		LatestAppVersion: procReplaceEmpty(latest.chart.AppVersion),
+		Distance:         distance,
+		Deprecated:       deprecated,
+	}, true
+}
+
+// releaseChartVersions returns the versions of the release chart, from the repository recorded on the release
+// and by the chart name in every repository for the releases installed without a recorded repository
+func (idx chartVersionIndex) releaseChartVersions(r *release.Release) []repoChartVersion {
+	repoName := releaseSourceRepo(r)
+	if repoName == "" {
+		return idx[r.Chart.Metadata.Name]
+	}
+
+	chartName := r.Labels[releaseLabelChart]
+	if chartName == "" {
+		chartName = r.Chart.Metadata.Name
+	}
+	versions := make([]repoChartVersion, 0)
+	for _, v := range idx[chartName] {
+		if v.repoName == repoName {
+			versions = append(versions, v)
+		}
+	}
+	return versions
+}
+
+// versionDistance is the most significant part of the version the latest version is ahead by
+func versionDistance(current *semver.Version, latest *semver.Version) string {
+	switch {
+	case !latest.GreaterThan(current):
+		return versionDistanceNone
+	case latest.Major() != current.Major():
+		return versionDistanceMajor
+	case latest.Minor() != current.Minor():
+		return versionDistanceMinor
+	}
+	return versionDistancePatch
+}
@@ -0,0 +1,273 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"go-api/config"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"syscall"
+	"time"
+)
+
+var (
+	blockedNets        []*net.IPNet
+	errBlockedAddress  = errors.New(common.REPO_URL_PRIVATE_ADDRESS)
+	repoConnectTimeout = 5 * time.Second
+	// the timeout of an index download, the same as the helm http getter
+	repoIndexTimeout = 120 * time.Second
+)
+
+func loadRepoPolicy() {
+	blockedNets = make([]*net.IPNet, 0, len(config.Env.RepoBlockedCIDRs))
+	for _, cidr := range config.Env.RepoBlockedCIDRs {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Errorf("[INVALID REPO BLOCKED CIDR] CIDR:: %v, ERR:: %v", cidr, err)
+			continue
+		}
+		blockedNets = append(blockedNets, ipNet)
+	}
+}
+
+// checkRepoURLPolicy checks the repository url against the configured scheme, host and address policy
+func checkRepoURLPolicy(repoURL string) error {
+	u, err := url.Parse(repoURL)
+	if err != nil || u.Hostname() == "" {
+		return fmt.Errorf(common.REPO_URL_INVALID)
+	}
+
+	if err := checkRepoURLScheme(u); err != nil {
+		return err
+	}
+
+	if !isAllowedRepoHost(u.Hostname()) {
+		return fmt.Errorf(common.REPO_URL_HOST_NOT_ALLOWED)
+	}
+
+	if len(blockedNets) > 0 {
+		ips, err := net.LookupIP(u.Hostname())
+		if err != nil {
+			log.Errorf("checkRepoURLPolicy:: lookup host (%s) :: %v", u.Hostname(), err)
+			return fmt.Errorf(common.REPO_CANNOT_BE_REACHED)
+		}
+		for _, ip := range ips {
+			if isBlockedIP(ip) {
+				log.Errorf("checkRepoURLPolicy:: blocked address (host: %s, ip: %s)", u.Hostname(), ip)
+				return errBlockedAddress
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkRepoURLScheme checks the scheme of a repository url, and of the urls its files are downloaded from
+func checkRepoURLScheme(u *url.URL) error {
+	switch u.Scheme {
+	case "https":
+	case "http":
+		if config.Env.RepoRequireHttps {
+			return fmt.Errorf(common.REPO_URL_HTTPS_REQUIRED)
+		}
+	default:
+		return fmt.Errorf(common.REPO_URL_INVALID)
+	}
+	return nil
+}
+
+func isAllowedRepoHost(host string) bool {
+	if len(config.Env.RepoAllowedHosts) < 1 {
+		return true
+	}
+	host = strings.ToLower(host)
+	for _, pattern := range config.Env.RepoAllowedHosts {
+		pattern = strings.ToLower(strings.TrimSpace(pattern))
+		if pattern == "" {
+			continue
+		}
+		if match, _ := path.Match(pattern, host); match {
+			return true
+		}
+	}
+	return false
+}
+
+func isBlockedIP(ip net.IP) bool {
+	for _, ipNet := range blockedNets {
+		if ipNet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// newRepoTransport returns a transport that refuses to connect to blocked addresses,
+// including addresses reached through redirects or dns changes after the policy check
+func newRepoTransport() *http.Transport {
+	dialer := &net.Dialer{
+		Timeout: repoConnectTimeout,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip != nil && isBlockedIP(ip) {
+				return errBlockedAddress
+			}
+			return nil
+		},
+	}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	return transport
+}
+
+// newRepoHttpClient returns the http client of the repository connectivity check
+func newRepoHttpClient() *http.Client {
+	return &http.Client{
+		Timeout:   repoConnectTimeout,
+		Transport: newRepoTransport(),
+	}
+}
+
+// repoIndexGetters replaces the helm http getter when the index file of a repository is downloaded,
+// so the download goes through the guarded transport and stops at the configured maximum index size
+func repoIndexGetters(entry *repo.Entry) getter.Providers {
+	return repoGetters(entry, config.Env.RepoMaxIndexSize)
+}
+
+// repoChartGetters replaces the helm http getter when a chart of a repository is downloaded,
+// the index may point the chart urls to other hosts so they go through the guarded transport as well
+func repoChartGetters(entry *repo.Entry) getter.Providers {
+	return repoGetters(entry, 0)
+}
+
+// repoGetters returns the getters of the repository files, a maxSize of 0 does not limit the size
+func repoGetters(entry *repo.Entry, maxSize int64) getter.Providers {
+	return getter.Providers{{
+		Schemes: []string{"http", "https"},
+		New: func(...getter.Option) (getter.Getter, error) {
+			transport := newRepoTransport()
+			tlsConf, err := repoTLSConfig(entry)
+			if err != nil {
+				return nil, err
+			}
+			transport.TLSClientConfig = tlsConf
+			return &repoGetter{
+				entry:   entry,
+				maxSize: maxSize,
+				client: &http.Client{
+					Timeout:   repoIndexTimeout,
+					Transport: transport,
+					CheckRedirect: func(req *http.Request, via []*http.Request) error {
+						if len(via) >= 10 {
+							return errors.New("stopped after 10 redirects")
+						}
+						return checkRepoURLScheme(req.URL)
+					},
+				},
+			}, nil
+		},
+	}}
+}
+
+type repoGetter struct {
+	entry   *repo.Entry
+	maxSize int64
+	client  *http.Client
+}
+
+// Get downloads the file with the credentials of the repository, they are only sent to the repository host
+func (g *repoGetter) Get(href string, _ ...getter.Option) (*bytes.Buffer, error) {
+	req, err := http.NewRequest(http.MethodGet, href, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRepoURLScheme(req.URL); err != nil {
+		log.Errorf("repoGetter:: url refused (url: %s) :: %v", href, err)
+		return nil, err
+	}
+	repoURL, err := url.Parse(g.entry.URL)
+	if err != nil {
+		return nil, fmt.Errorf(common.REPO_URL_INVALID)
+	}
+	if g.entry.PassCredentialsAll || (repoURL.Scheme == req.URL.Scheme && repoURL.Host == req.URL.Host) {
+		if g.entry.Username != "" && g.entry.Password != "" {
+			req.SetBasicAuth(g.entry.Username, g.entry.Password)
+		}
+	}
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		if errors.Is(err, errBlockedAddress) {
+			return nil, errBlockedAddress
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s : %s", href, resp.Status)
+	}
+
+	maxSize := g.maxSize
+	body := io.Reader(resp.Body)
+	if maxSize > 0 {
+		if resp.ContentLength > maxSize {
+			log.Errorf("repoGetter:: index too large (url: %s, size: %d, max: %d)", href, resp.ContentLength, maxSize)
+			return nil, fmt.Errorf(common.REPO_INDEX_TOO_LARGE)
+		}
+		// one byte past the maximum tells a body of the maximum size from a larger one
+		body = io.LimitReader(resp.Body, maxSize+1)
+	}
+	buf := bytes.NewBuffer(nil)
+	if _, err := io.Copy(buf, body); err != nil {
+		return nil, err
+	}
+	if maxSize > 0 && int64(buf.Len()) > maxSize {
+		log.Errorf("repoGetter:: index too large (url: %s, max: %d)", href, maxSize)
+		return nil, fmt.Errorf(common.REPO_INDEX_TOO_LARGE)
+	}
+	return buf, nil
+}
+
+// repoTLSConfig returns the tls configuration of the ca, client certificate and insecure settings of the repository
+func repoTLSConfig(entry *repo.Entry) (*tls.Config, error) {
+	tlsConf := &tls.Config{InsecureSkipVerify: entry.InsecureSkipTLSverify}
+	if entry.CertFile != "" && entry.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(entry.CertFile, entry.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf.Certificates = []tls.Certificate{cert}
+	}
+	if entry.CAFile != "" {
+		ca, err := os.ReadFile(entry.CAFile)
+		if err != nil {
+			log.Errorf("repoTLSConfig:: failed read ca file (name: %s, path: %s) :: %v", entry.Name, entry.CAFile, err)
+			return nil, fmt.Errorf(common.REPO_CA_FAILED_READ)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf(common.REPO_CA_INVALID)
+		}
+		tlsConf.RootCAs = pool
+	}
+	return tlsConf, nil
+}
@@ -0,0 +1,407 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"go-api/config"
+	"golang.org/x/crypto/openpgp" //nolint
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/downloader"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/repo"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+const (
+	verifyModeNone    = "none"
+	verifyModeWarn    = "warn"
+	verifyModeRequire = "require"
+
+	signatureSkipped  = "skipped"
+	signatureVerified = "verified"
+	signatureUnsigned = "unsigned"
+	signatureFailed   = "failed"
+
+	keyringFileExt       = ".gpg"
+	verificationFileName = "verification.yaml"
+)
+
+type repoVerification struct {
+	Mode    string `json:"mode"`
+	Keyring string `json:"keyring"`
+}
+
+type addKeyringElement struct {
+	Name    string `json:"name"`
+	Keyring string `json:"keyring"`
+}
+
+type keyringElement struct {
+	Name string       `json:"name"`
+	Keys []keyringKey `json:"keys"`
+}
+
+type keyringKey struct {
+	KeyId       string   `json:"key_id"`
+	Fingerprint string   `json:"fingerprint"`
+	Identities  []string `json:"identities"`
+}
+
+type chartSignature struct {
+	Mode     string   `json:"mode"`
+	Status   string   `json:"status"`
+	SignedBy []string `json:"signed_by"`
+	FileHash string   `json:"file_hash"`
+	Message  string   `json:"message"`
+}
+
+// ListKeyrings
+// @Summary List Keyrings
+// @Tags Keyrings
+// @Accept json
+// @Produce json
+// @Router /api/keyrings [Get]
+func ListKeyrings(c *fiber.Ctx) error {
+	files, err := filepath.Glob(filepath.Join(config.Env.HelmRepoKeyring, "*"+keyringFileExt))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	keyrings := make([]keyringElement, 0, len(files))
+	for _, f := range files {
+		name := strings.TrimSuffix(filepath.Base(f), keyringFileExt)
+		entities, err := readKeyringFile(f)
+		if err != nil {
+			log.Errorf("ListKeyrings:: failed read keyring (name: %s) :: %v", name, err)
+			continue
+		}
+		keyrings = append(keyrings, keyringElement{Name: name, Keys: keyringKeys(entities)})
+	}
+
+	return common.RespOK(c, keyrings)
+}
+
+// AddKeyring
+// @Summary Add Keyring
+// @Tags Keyrings
+// @Accept json
+// @Produce json
+// @Router /api/keyrings [Post]
+func AddKeyring(c *fiber.Ctx) error {
+	newKeyring := new(addKeyringElement)
+	if err := c.BodyParser(newKeyring); err != nil {
+		return common.RespErr(c, err)
+	}
+	if match, _ := regexp.MatchString(common.REPO_NAME_REGEXP_PATTERN, newKeyring.Name); !match {
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_NAME_INVALID))
+	}
+
+	path := keyringPath(newKeyring.Name)
+	if FileExists(path) {
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_ALREADY_EXISTS))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(newKeyring.Keyring)
+	if err != nil {
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_INVALID))
+	}
+
+	// accept both binary and ascii armored public keyrings, store them as binary for helm
+	entities, err := openpgp.ReadKeyRing(bytes.NewReader(data))
+	if err != nil {
+		entities, err = openpgp.ReadArmoredKeyRing(bytes.NewReader(data))
+	}
+	if err != nil || len(entities) == 0 {
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_INVALID))
+	}
+
+	buf := new(bytes.Buffer)
+	for _, e := range entities {
+		if err := e.Serialize(buf); err != nil {
+			return common.RespErr(c, fmt.Errorf(common.KEYRING_INVALID))
+		}
+	}
+
+	if err := os.MkdirAll(config.Env.HelmRepoKeyring, os.ModePerm); err != nil {
+		return common.RespErr(c, err)
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0644); err != nil {
+		log.Errorf("AddKeyring:: failed write keyring (name: %s) :: %v", newKeyring.Name, err)
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_FAILED_SAVE))
+	}
+
+	return common.RespOK(c, keyringElement{Name: newKeyring.Name, Keys: keyringKeys(entities)})
+}
+
+// RemoveKeyring
+// @Summary Remove Keyring
+// @Tags Keyrings
+// @Accept json
+// @Produce json
+// @Router /api/keyrings/:keyring [Delete]
+func RemoveKeyring(c *fiber.Ctx) error {
+	name := c.Params("keyring")
+	path := keyringPath(name)
+	if !FileExists(path) {
+		return common.RespErr(c, fmt.Errorf(common.KEYRING_NOT_FOUND))
+	}
+
+	verifications, err := loadRepoVerifications()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	for _, v := range verifications {
+		if v.Keyring == name {
+			return common.RespErr(c, fmt.Errorf(common.KEYRING_IN_USE))
+		}
+	}
+
+	if err := RemoveFile(path); err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, nil)
+}
+
+// GetRepoVerification
+// @Summary Get Repository Verification
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/verification [Get]
+func GetRepoVerification(c *fiber.Ctx) error {
+	verification, err := getRepoVerification(c.Params("repositories"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, verification)
+}
+
+// SetRepoVerification
+// @Summary Set Repository Verification
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/verification [Put]
+func SetRepoVerification(c *fiber.Ctx) error {
+	repoName := c.Params("repositories")
+	verification := new(repoVerification)
+	if err := c.BodyParser(verification); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	switch verification.Mode {
+	case verifyModeNone:
+		verification.Keyring = ""
+	case verifyModeWarn, verifyModeRequire:
+		if !FileExists(keyringPath(verification.Keyring)) {
+			return common.RespErr(c, fmt.Errorf(common.KEYRING_NOT_FOUND))
+		}
+	default:
+		return common.RespErr(c, fmt.Errorf(common.VERIFY_MODE_INVALID))
+	}
+
+	repoFile, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		return common.RespErr(c, fmt.Errorf(common.REPO_FAILED_LOADING_FILE))
+	}
+	if !repoFile.Has(repoName) {
+		return common.RespErr(c, fmt.Errorf(common.REPO_NO_NAMED_FOUND))
+	}
+
+	verifications, err := loadRepoVerifications()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	if verification.Mode == verifyModeNone {
+		delete(verifications, repoName)
+	} else {
+		verifications[repoName] = *verification
+	}
+	if err := saveRepoVerifications(verifications); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	return common.RespOK(c, verification)
+}
+
+// locateRepoChart downloads the repository chart and verifies its provenance
+// according to the verification mode of the repository
+func locateRepoChart(opts *action.ChartPathOptions, repoName string, chartName string) (string, *chartSignature, error) {
+	aimChart := fmt.Sprintf("%s/%s", repoName, chartName)
+	// the charts of a repository are not downloaded when its verification mode cannot be read
+	verification, err := getRepoVerification(repoName)
+	if err != nil {
+		return "", nil, err
+	}
+	signature := &chartSignature{
+		Mode:     verification.Mode,
+		Status:   signatureSkipped,
+		SignedBy: make([]string, 0),
+	}
+
+	repoFile, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		return "", nil, fmt.Errorf(common.REPO_FAILED_LOADING_FILE)
+	}
+	entry := repoFile.Get(repoName)
+	if entry == nil {
+		return "", nil, fmt.Errorf(common.REPO_NO_NAMED_FOUND)
+	}
+	// the chart urls of the index are downloaded through the guarded getters of the repository,
+	// the hosted repository is served by this server so its local url is not guarded
+	getters := repoChartGetters(entry)
+	if isHostedRepo(repoName) {
+		getters = getter.All(settings)
+	}
+	dl := downloader.ChartDownloader{
+		Out:              io.Discard,
+		Verify:           downloader.VerifyNever,
+		Getters:          getters,
+		RepositoryConfig: settings.RepositoryConfig,
+		RepositoryCache:  settings.RepositoryCache,
+	}
+
+	if verification.Mode == verifyModeNone {
+		cp, _, err := dl.DownloadTo(aimChart, opts.Version, settings.RepositoryCache)
+		if err != nil {
+			return "", nil, err
+		}
+		recordChartSchema(cp)
+		return cp, signature, nil
+	}
+
+	dl.Verify = downloader.VerifyLater
+	// the chart and its provenance file are downloaded apart from the other requests,
+	// so the provenance file that is verified is the one of this download
+	tmpDir, err := os.MkdirTemp(settings.RepositoryCache, "verify-")
+	if err != nil {
+		return "", nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+	tmpPath, _, err := dl.DownloadTo(aimChart, opts.Version, tmpDir)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if !FileExists(tmpPath + ".prov") {
+		signature.Status = signatureUnsigned
+		signature.Message = "provenance file not found"
+	} else if ver, err := downloader.VerifyChart(tmpPath, keyringPath(verification.Keyring)); err != nil {
+		signature.Status = signatureFailed
+		signature.Message = err.Error()
+	} else {
+		signature.Status = signatureVerified
+		signature.FileHash = ver.FileHash
+		for identity := range ver.SignedBy.Identities {
+			signature.SignedBy = append(signature.SignedBy, identity)
+		}
+		sort.Strings(signature.SignedBy)
+	}
+
+	if signature.Status != signatureVerified {
+		log.Warnf("chart verification:: chart: %s, version: %s, mode: %s, status: %s, message: %s",
+			aimChart, opts.Version, signature.Mode, signature.Status, signature.Message)
+		if verification.Mode == verifyModeRequire {
+			return "", signature, fmt.Errorf(common.CHART_VERIFICATION_FAILED)
+		}
+	}
+
+	// the archive is kept in the repository cache like the charts located without verification
+	cp := filepath.Join(settings.RepositoryCache, filepath.Base(tmpPath))
+	if err := os.Rename(tmpPath, cp); err != nil {
+		return "", nil, err
+	}
+	recordChartSchema(cp)
+	return cp, signature, nil
+}
+
+func getRepoVerification(repoName string) (repoVerification, error) {
+	verifications, err := loadRepoVerifications()
+	if err != nil {
+		return repoVerification{}, err
+	}
+	if v, ok := verifications[repoName]; ok {
+		return v, nil
+	}
+	return repoVerification{Mode: verifyModeNone}, nil
+}
+
+func removeRepoVerification(repoName string) error {
+	verifications, err := loadRepoVerifications()
+	if err != nil {
+		return err
+	}
+	if _, ok := verifications[repoName]; !ok {
+		return nil
+	}
+	delete(verifications, repoName)
+	return saveRepoVerifications(verifications)
+}
+
+func loadRepoVerifications() (map[string]repoVerification, error) {
+	verifications := map[string]repoVerification{}
+	data, err := os.ReadFile(filepath.Join(config.Env.HelmRepoKeyring, verificationFileName))
+	if os.IsNotExist(err) {
+		return verifications, nil
+	}
+	if err != nil {
+		log.Errorf("loadRepoVerifications:: failed read file :: %v", err)
+		return nil, fmt.Errorf(common.VERIFICATION_FAILED_LOADING_FILE)
+	}
+	if err := yaml.Unmarshal(data, &verifications); err != nil {
+		log.Errorf("loadRepoVerifications:: failed parse file :: %v", err)
+		return nil, fmt.Errorf(common.VERIFICATION_FAILED_LOADING_FILE)
+	}
+	return verifications, nil
+}
+
+func saveRepoVerifications(verifications map[string]repoVerification) error {
+	data, err := yaml.Marshal(verifications)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(config.Env.HelmRepoKeyring, os.ModePerm); err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(config.Env.HelmRepoKeyring, verificationFileName), data, 0600)
+}
+
+func keyringPath(name string) string {
+	return filepath.Join(config.Env.HelmRepoKeyring, filepath.Base(name)+keyringFileExt)
+}
+
+func readKeyringFile(path string) (openpgp.EntityList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return openpgp.ReadKeyRing(f)
+}
+
+func keyringKeys(entities openpgp.EntityList) []keyringKey {
+	keys := make([]keyringKey, 0, len(entities))
+	for _, e := range entities {
+		key := keyringKey{
+			KeyId:       e.PrimaryKey.KeyIdString(),
+			Fingerprint: fmt.Sprintf("%X", e.PrimaryKey.Fingerprint),
+			Identities:  make([]string, 0, len(e.Identities)),
+		}
+		for identity := range e.Identities {
+			key.Identities = append(key.Identities, identity)
+		}
+		sort.Strings(key.Identities)
+		keys = append(keys, key)
+	}
+	return keys
+}
@@ -0,0 +1,276 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes"
+	"sort"
+)
+
+// workloads whose pods are found by their label selector
+var podSelectorKinds = map[string]bool{
+	"Deployment":            true,
+	"ReplicaSet":            true,
+	"ReplicationController": true,
+	"StatefulSet":           true,
+	"DaemonSet":             true,
+	"Job":                   true,
+}
+
+type releaseEventElement struct {
+	Type           string `json:"type"`
+	Reason         string `json:"reason"`
+	Message        string `json:"message"`
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Namespace      string `json:"namespace"`
+	Count          int32  `json:"count"`
+	Source         string `json:"source"`
+	FirstTimestamp string `json:"firstTimestamp"`
+	LastTimestamp  string `json:"lastTimestamp"`
+}
+
+type namespaceError struct {
+	Namespace     string `json:"namespace"`
+	ResultCode    string `json:"resultCode"`
+	DetailMessage string `json:"detailMessage"`
+}
+
+// involvedObject identifies the object of an event
+type involvedObject struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// GetReleaseEvents
+// @Summary Get Release Events
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/events [Get]
+func GetReleaseEvents(c *fiber.Ctx) error {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	if len(lse.Sort) == 0 {
+		lse.Sort, _ = parseListSort("-lastTimestamp,kind,name")
+	}
+
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	rel, err := action.NewGet(actionConfig).Run(c.Params("release"))
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, err)
+	}
+	clientSet, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	mapper, err := actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	ctx := c.Context()
+	objects, err := releaseObjects(rel, mapper)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	involved := map[involvedObject]bool{}
+	namespaces := map[string]bool{}
+	for _, obj := range objects {
+		// the events of cluster-scoped objects are not in the namespaces of the release
+		if obj.GetNamespace() == "" {
+			continue
+		}
+		involved[involvedObject{obj.GetKind(), obj.GetNamespace(), obj.GetName()}] = true
+		namespaces[obj.GetNamespace()] = true
+	}
+	// the pods of the workloads are where most of the failures are reported
+	pods, err := releasePods(ctx, clientSet, mapper, rel)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	for _, pod := range pods {
+		involved[involvedObject{"Pod", pod.Namespace, pod.Name}] = true
+		for _, owner := range pod.OwnerReferences {
+			involved[involvedObject{owner.Kind, pod.Namespace, owner.Name}] = true
+		}
+	}
+
+	elements := make([]interface{}, 0)
+	errs := make([]namespaceError, 0)
+	for namespace := range namespaces {
+		events, err := clientSet.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+		if err != nil {
+			log.Errorf("GetReleaseEvents:: namespace: %s :: %v", namespace, err)
+			errs = append(errs, namespaceError{
+				Namespace:     namespace,
+				ResultCode:    err.Error(),
+				DetailMessage: common.Localize(c, err.Error()),
+			})
+			continue
+		}
+		for _, e := range events.Items {
+			o := e.InvolvedObject
+			if involved[involvedObject{o.Kind, o.Namespace, o.Name}] {
+				elements = append(elements, constructReleaseEventElement(e))
+			}
+		}
+	}
+
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Namespace < errs[j].Namespace })
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.PartialListRespOK(c, itemCount, resultData, errs)
+}
+
+// releaseObjects returns the objects of the manifest and the hooks of the release,
+// namespaced objects without a namespace are in the release namespace, cluster-scoped objects keep an empty namespace
+func releaseObjects(rel *release.Release, mapper meta.RESTMapper) ([]*unstructured.Unstructured, error) {
+	objects, err := ParseManifestObjects(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range rel.Hooks {
+		hookObjects, err := ParseManifestObjects(h.Manifest)
+		if err != nil {
+			return nil, err
+		}
+		objects = append(objects, hookObjects...)
+	}
+	for _, obj := range objects {
+		if obj.GetNamespace() != "" {
+			continue
+		}
+		gvk := obj.GroupVersionKind()
+		mapping, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err == nil && mapping.Scope.Name() == meta.RESTScopeNameRoot {
+			continue
+		}
+		// kinds unknown to the cluster are taken as namespaced
+		obj.SetNamespace(rel.Namespace)
+	}
+	return objects, nil
+}
+
+// releasePods returns the pods of the release, the pods of its manifest and those selected by its workloads
+func releasePods(ctx context.Context, clientSet kubernetes.Interface, mapper meta.RESTMapper, rel *release.Release) ([]v1.Pod, error) {
+	objects, err := releaseObjects(rel, mapper)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := map[string]bool{}
+	pods := make([]v1.Pod, 0)
+	addPod := func(pod v1.Pod) {
+		key := pod.Namespace + "/" + pod.Name
+		if !seen[key] {
+			seen[key] = true
+			pods = append(pods, pod)
+		}
+	}
+
+	for _, obj := range objects {
+		switch {
+		case obj.GetKind() == "Pod":
+			pod, err := clientSet.CoreV1().Pods(obj.GetNamespace()).Get(ctx, obj.GetName(), metav1.GetOptions{})
+			if err != nil {
+				// hook pods may be deleted by their delete policy
+				continue
+			}
+			addPod(*pod)
+		case podSelectorKinds[obj.GetKind()]:
+			selector, err := workloadPodSelector(obj)
+			if err != nil || selector.Empty() {
+				continue
+			}
+			list, err := clientSet.CoreV1().Pods(obj.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+			if err != nil {
+				return nil, err
+			}
+			for _, pod := range list.Items {
+				addPod(pod)
+			}
+		}
+	}
+	return pods, nil
+}
+
+// workloadPodSelector returns the pod selector of a workload, jobs without one select their pods by job name
+func workloadPodSelector(obj *unstructured.Unstructured) (labels.Selector, error) {
+	raw, found, err := unstructured.NestedMap(obj.Object, "spec", "selector")
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		if obj.GetKind() == "Job" {
+			return labels.SelectorFromSet(labels.Set{"job-name": obj.GetName()}), nil
+		}
+		return labels.Nothing(), nil
+	}
+	// replication controllers select by a plain label map
+	if obj.GetKind() == "ReplicationController" {
+		set := labels.Set{}
+		for k, v := range raw {
+			if s, ok := v.(string); ok {
+				set[k] = s
+			}
+		}
+		return labels.SelectorFromSet(set), nil
+	}
+
+	var selector metav1.LabelSelector
+	if err := runtime.DefaultUnstructuredConverter.FromUnstructured(raw, &selector); err != nil {
+		return nil, err
+	}
+	return metav1.LabelSelectorAsSelector(&selector)
+}
+
+func constructReleaseEventElement(e v1.Event) releaseEventElement {
+	first, last := e.FirstTimestamp.Time, e.LastTimestamp.Time
+	// events of the events.k8s.io api only set the event time
+	if last.IsZero() {
+		last = e.EventTime.Time
+	}
+	if first.IsZero() {
+		first = last
+	}
+	source := e.Source.Component
+	if source == "" {
+		source = e.ReportingController
+	}
+	return releaseEventElement{
+		Type:           e.Type,
+		Reason:         e.Reason,
+		Message:        e.Message,
+		Kind:           e.InvolvedObject.Kind,
+		Name:           e.InvolvedObject.Name,
+		Namespace:      e.InvolvedObject.Namespace,
+		Count:          e.Count,
+		Source:         source,
+		FirstTimestamp: formatDateTime(first),
+		LastTimestamp:  formatDateTime(last),
+	}
+}
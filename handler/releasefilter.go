@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/labels"
+	"strings"
+)
+
+// releaseStatusFlags maps the status filter values to the state flags of the helm list action
+var releaseStatusFlags = map[string]func(client *action.List){
+	"deployed":     func(client *action.List) { client.Deployed = true },
+	"failed":       func(client *action.List) { client.Failed = true },
+	"pending":      func(client *action.List) { client.Pending = true },
+	"uninstalled":  func(client *action.List) { client.Uninstalled = true },
+	"uninstalling": func(client *action.List) { client.Uninstalling = true },
+	"superseded":   func(client *action.List) { client.Superseded = true },
+}
+
+type releaseListFilter struct {
+	Statuses     []string
+	Selector     string
+	Chart        string
+	ChartVersion *semver.Constraints
+}
+
+// releaseListFilterCheck parses the release filters, status=deployed,failed maps to the helm list state flags
+// and selector filters by the release storage labels, all the states are listed without a status
+func releaseListFilterCheck(c *fiber.Ctx) (*releaseListFilter, error) {
+	rlf := &releaseListFilter{
+		Statuses: splitListParam(strings.ToLower(c.Query("status")), ","),
+		Selector: c.Query("selector"),
+		Chart:    strings.TrimSpace(c.Query("chart")),
+	}
+
+	for _, s := range rlf.Statuses {
+		if _, ok := releaseStatusFlags[s]; !ok {
+			return nil, fmt.Errorf(common.RELEASE_FILTER_INVALID)
+		}
+	}
+	if _, err := labels.Parse(rlf.Selector); err != nil {
+		return nil, fmt.Errorf(common.RELEASE_FILTER_INVALID)
+	}
+	if version := strings.TrimSpace(c.Query("chartVersion")); version != "" {
+		constraint, err := semver.NewConstraint(version)
+		if err != nil {
+			return nil, fmt.Errorf(common.RELEASE_FILTER_INVALID)
+		}
+		rlf.ChartVersion = constraint
+	}
+	return rlf, nil
+}
+
+// run lists the latest revision of the releases matching the filter, newest first
+func (f *releaseListFilter) run(actionConfig *action.Configuration) ([]*release.Release, error) {
+	client := action.NewList(actionConfig)
+	client.All = len(f.Statuses) == 0
+	client.ByDate = true
+	client.SortReverse = true
+	client.Selector = f.Selector
+	for _, s := range f.Statuses {
+		releaseStatusFlags[s](client)
+	}
+	client.SetStateMask()
+
+	results, err := client.Run()
+	if err != nil {
+		return nil, err
+	}
+
+	matched := make([]*release.Release, 0, len(results))
+	for _, r := range results {
+		if f.matchChart(r) {
+			matched = append(matched, r)
+		}
+	}
+	return matched, nil
+}
+
+func (f *releaseListFilter) matchChart(r *release.Release) bool {
+	if r.Chart == nil || r.Chart.Metadata == nil {
+		return f.Chart == "" && f.ChartVersion == nil
+	}
+	if f.Chart != "" && f.Chart != r.Chart.Metadata.Name {
+		return false
+	}
+	if f.ChartVersion != nil {
+		v, err := semver.NewVersion(r.Chart.Metadata.Version)
+		if err != nil || !f.ChartVersion.Check(v) {
+			return false
+		}
+	}
+	return true
+}
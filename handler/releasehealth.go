@@ -0,0 +1,259 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/gofiber/fiber/v2/log"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/kubernetes"
+	"sync"
+)
+
+const (
+	healthHealthy     = "Healthy"
+	healthProgressing = "Progressing"
+	healthDegraded    = "Degraded"
+	healthMissing     = "Missing"
+
+	// releases checked at once when the health is listed
+	healthConcurrency = 8
+)
+
+// healthSeverity orders the statuses, the release takes the most severe status of its resources
+var healthSeverity = map[string]int{
+	healthHealthy:     0,
+	healthProgressing: 1,
+	healthMissing:     2,
+	healthDegraded:    3,
+}
+
+type releaseHealth struct {
+	Status    string           `json:"status"`
+	Resources []resourceHealth `json:"resources,omitempty"`
+}
+
+type resourceHealth struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace"`
+	Status    string `json:"status"`
+	Message   string `json:"message,omitempty"`
+}
+
+// resourceHealthChecks reads the health of the kinds the release health is derived from
+var resourceHealthChecks = map[string]func(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error){
+	"Deployment":            deploymentHealth,
+	"StatefulSet":           statefulSetHealth,
+	"DaemonSet":             daemonSetHealth,
+	"Job":                   jobHealth,
+	"PersistentVolumeClaim": pvcHealth,
+	"Service":               serviceHealth,
+}
+
+// computeReleaseHealth derives the health of the release from the workloads, claims and services of its manifest,
+// a release without any of them is healthy
+func computeReleaseHealth(ctx context.Context, clientSet kubernetes.Interface, rel *release.Release) (*releaseHealth, error) {
+	objects, err := ParseManifestObjects(rel.Manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	health := &releaseHealth{Status: healthHealthy, Resources: make([]resourceHealth, 0)}
+	for _, obj := range objects {
+		check, ok := resourceHealthChecks[obj.GetKind()]
+		if !ok {
+			continue
+		}
+		resource := checkResourceHealth(ctx, clientSet, obj, rel.Namespace, check)
+		if healthSeverity[resource.Status] > healthSeverity[health.Status] {
+			health.Status = resource.Status
+		}
+		health.Resources = append(health.Resources, resource)
+	}
+	return health, nil
+}
+
+func checkResourceHealth(ctx context.Context, clientSet kubernetes.Interface, obj *unstructured.Unstructured, releaseNamespace string,
+	check func(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error)) resourceHealth {
+	namespace := obj.GetNamespace()
+	if namespace == "" {
+		namespace = releaseNamespace
+	}
+	resource := resourceHealth{Kind: obj.GetKind(), Name: obj.GetName(), Namespace: namespace}
+
+	status, message, err := check(ctx, clientSet, namespace, obj.GetName())
+	switch {
+	case apierrors.IsNotFound(err):
+		resource.Status, resource.Message = healthMissing, "resource not found"
+	case err != nil:
+		// a resource that cannot be read is not known to be healthy
+		resource.Status, resource.Message = healthDegraded, err.Error()
+	default:
+		resource.Status, resource.Message = status, message
+	}
+	return resource
+}
+
+// releaseHealthOf computes the health of a release with the clients of the action configuration,
+// the health is left out when the cluster cannot be read
+func releaseHealthOf(ctx context.Context, actionConfig *action.Configuration, rel *release.Release) *releaseHealth {
+	clientSet, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		log.Errorf("releaseHealthOf:: release: %s :: %v", rel.Name, err)
+		return nil
+	}
+	health, err := computeReleaseHealth(ctx, clientSet, rel)
+	if err != nil {
+		log.Errorf("releaseHealthOf:: release: %s :: %v", rel.Name, err)
+		return nil
+	}
+	return health
+}
+
+// listReleaseHealth computes the health status of the releases, without the resources, a bounded number at once
+func listReleaseHealth(ctx context.Context, actionConfig *action.Configuration, releases []*release.Release) []*releaseHealth {
+	healths := make([]*releaseHealth, len(releases))
+	clientSet, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		log.Errorf("listReleaseHealth:: %v", err)
+		return healths
+	}
+
+	sem := make(chan struct{}, healthConcurrency)
+	var wg sync.WaitGroup
+	for i, rel := range releases {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, rel *release.Release) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			health, err := computeReleaseHealth(ctx, clientSet, rel)
+			if err != nil {
+				log.Errorf("listReleaseHealth:: release: %s :: %v", rel.Name, err)
+				return
+			}
+			health.Resources = nil
+			healths[i] = health
+		}(i, rel)
+	}
+	wg.Wait()
+	return healths
+}
+
+func deploymentHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	d, err := clientSet.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	for _, cond := range d.Status.Conditions {
+		if cond.Type == appsv1.DeploymentProgressing && cond.Status == v1.ConditionFalse {
+			return healthDegraded, cond.Message, nil
+		}
+		if cond.Type == appsv1.DeploymentReplicaFailure && cond.Status == v1.ConditionTrue {
+			return healthDegraded, cond.Message, nil
+		}
+	}
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		return healthProgressing, "waiting for the rollout to be observed", nil
+	case d.Status.UpdatedReplicas < replicas:
+		return healthProgressing, fmt.Sprintf("%d of %d replicas updated", d.Status.UpdatedReplicas, replicas), nil
+	case d.Status.AvailableReplicas < replicas:
+		return healthProgressing, fmt.Sprintf("%d of %d replicas available", d.Status.AvailableReplicas, replicas), nil
+	}
+	return healthHealthy, "", nil
+}
+
+func statefulSetHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	s, err := clientSet.AppsV1().StatefulSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	switch {
+	case s.Generation > s.Status.ObservedGeneration:
+		return healthProgressing, "waiting for the rollout to be observed", nil
+	case s.Status.ReadyReplicas < replicas:
+		return healthProgressing, fmt.Sprintf("%d of %d replicas ready", s.Status.ReadyReplicas, replicas), nil
+	case s.Spec.UpdateStrategy.Type != appsv1.OnDeleteStatefulSetStrategyType && s.Status.UpdateRevision != s.Status.CurrentRevision:
+		return healthProgressing, fmt.Sprintf("%d of %d replicas updated", s.Status.UpdatedReplicas, replicas), nil
+	}
+	return healthHealthy, "", nil
+}
+
+func daemonSetHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	d, err := clientSet.AppsV1().DaemonSets(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	desired := d.Status.DesiredNumberScheduled
+	switch {
+	case d.Generation > d.Status.ObservedGeneration:
+		return healthProgressing, "waiting for the rollout to be observed", nil
+	case d.Status.UpdatedNumberScheduled < desired:
+		return healthProgressing, fmt.Sprintf("%d of %d pods updated", d.Status.UpdatedNumberScheduled, desired), nil
+	case d.Status.NumberAvailable < desired:
+		return healthProgressing, fmt.Sprintf("%d of %d pods available", d.Status.NumberAvailable, desired), nil
+	}
+	return healthHealthy, "", nil
+}
+
+func jobHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	j, err := clientSet.BatchV1().Jobs(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	for _, cond := range j.Status.Conditions {
+		if cond.Status != v1.ConditionTrue {
+			continue
+		}
+		switch cond.Type {
+		case batchv1.JobFailed:
+			return healthDegraded, cond.Message, nil
+		case batchv1.JobComplete:
+			return healthHealthy, "", nil
+		}
+	}
+	return healthProgressing, fmt.Sprintf("%d active, %d succeeded", j.Status.Active, j.Status.Succeeded), nil
+}
+
+func pvcHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	p, err := clientSet.CoreV1().PersistentVolumeClaims(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	switch p.Status.Phase {
+	case v1.ClaimBound:
+		return healthHealthy, "", nil
+	case v1.ClaimLost:
+		return healthDegraded, "the bound volume is lost", nil
+	}
+	return healthProgressing, "waiting for the claim to be bound", nil
+}
+
+func serviceHealth(ctx context.Context, clientSet kubernetes.Interface, namespace string, name string) (string, string, error) {
+	s, err := clientSet.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", "", err
+	}
+	if s.Spec.Type == v1.ServiceTypeLoadBalancer && len(s.Status.LoadBalancer.Ingress) == 0 {
+		return healthProgressing, "waiting for the load balancer address", nil
+	}
+	return healthHealthy, "", nil
+}
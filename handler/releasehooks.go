@@ -0,0 +1,159 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"sort"
+	"strconv"
+)
+
+type releaseHookElement struct {
+	Name           string   `json:"name"`
+	Kind           string   `json:"kind"`
+	Path           string   `json:"path"`
+	Events         []string `json:"events"`
+	Weight         int      `json:"weight"`
+	DeletePolicies []string `json:"delete_policies"`
+	Manifest       string   `json:"manifest,omitempty"`
+	Phase          string   `json:"phase"`
+	StartedAt      string   `json:"started_at"`
+	CompletedAt    string   `json:"completed_at"`
+}
+
+// failedHookElement is a hook that failed an install or upgrade, its manifest is left out as it may hold secret data
+type failedHookElement struct {
+	Name  string `json:"name"`
+	Kind  string `json:"kind"`
+	Phase string `json:"phase"`
+	Error string `json:"error"`
+}
+
+// GetReleaseHooks
+// @Summary Get Release Hooks
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/hooks [Get]
+func GetReleaseHooks(c *fiber.Ctx) error {
+	lse, err := ListSearchCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	// revision=n shows the hooks as they ran for a past revision, the latest revision without it
+	revision := 0
+	if value := c.Query("revision"); value != "" {
+		if revision, err = strconv.Atoi(value); err != nil || revision < 1 {
+			return common.RespErr(c, fmt.Errorf(common.REVISION_NUMBER_INVALID))
+		}
+	}
+	event := c.Query("event")
+	manifest, err := strconv.ParseBool(c.Query("manifest", "true"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	client := action.NewGet(actionConfig)
+	client.Version = revision
+	rel, err := client.Run(c.Params("release"))
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, err)
+	}
+
+	elements := make([]interface{}, 0, len(rel.Hooks))
+	for _, h := range sortedReleaseHooks(rel.Hooks) {
+		if event != "" && !hookHasEvent(h, event) {
+			continue
+		}
+		element := constructReleaseHookElement(h)
+		if !manifest {
+			element.Manifest = ""
+		}
+		elements = append(elements, element)
+	}
+
+	itemCount, resultData := ResourceListProcessing(elements, lse)
+	return common.ListRespOK(c, itemCount, resultData)
+}
+
+// failedReleaseHooks returns the hooks that failed while installing or upgrading the release with the error of the action
+func failedReleaseHooks(rel *release.Release, err error) []failedHookElement {
+	failed := make([]failedHookElement, 0)
+	if rel == nil {
+		return failed
+	}
+	for _, h := range sortedReleaseHooks(rel.Hooks) {
+		if h.LastRun.Phase == release.HookPhaseFailed {
+			failed = append(failed, failedHookElement{
+				Name:  h.Name,
+				Kind:  h.Kind,
+				Phase: h.LastRun.Phase.String(),
+				Error: err.Error(),
+			})
+		}
+	}
+	return failed
+}
+
+// respReleaseErr responds a failed install or upgrade with the hooks that failed it
+func respReleaseErr(c *fiber.Ctx, rel *release.Release, err error) error {
+	if failed := failedReleaseHooks(rel, err); len(failed) > 0 {
+		return common.RespErrItems(c, err, failed)
+	}
+	return respValuesErr(c, err)
+}
+
+// sortedReleaseHooks orders the hooks the way helm runs them, by weight and then name
+func sortedReleaseHooks(hooks []*release.Hook) []*release.Hook {
+	sorted := append([]*release.Hook{}, hooks...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Weight != sorted[j].Weight {
+			return sorted[i].Weight < sorted[j].Weight
+		}
+		return sorted[i].Name < sorted[j].Name
+	})
+	return sorted
+}
+
+func hookHasEvent(h *release.Hook, event string) bool {
+	for _, e := range h.Events {
+		if e.String() == event {
+			return true
+		}
+	}
+	return false
+}
+
+func constructReleaseHookElement(h *release.Hook) releaseHookElement {
+	element := releaseHookElement{
+		Name:           h.Name,
+		Kind:           h.Kind,
+		Path:           h.Path,
+		Events:         make([]string, 0, len(h.Events)),
+		Weight:         h.Weight,
+		DeletePolicies: make([]string, 0, len(h.DeletePolicies)),
+		Manifest:       h.Manifest,
+		Phase:          procReplaceEmpty(h.LastRun.Phase.String()),
+		StartedAt:      formatDateTime(h.LastRun.StartedAt.Time),
+		CompletedAt:    formatDateTime(h.LastRun.CompletedAt.Time),
+	}
+	for _, e := range h.Events {
+		element.Events = append(element.Events, e.String())
+	}
+	for _, p := range h.DeletePolicies {
+		element.DeletePolicies = append(element.DeletePolicies, p.String())
+	}
+	return element
+}
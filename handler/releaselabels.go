@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"regexp"
+	"strings"
+)
+
+// release labels recording where the chart of a release came from and who deployed it,
+// helm does not keep the repository of a chart
+const (
+	releaseLabelRepo        = "catalog-api/repo"
+	releaseLabelChart       = "catalog-api/chart"
+	releaseLabelInstalledBy = "catalog-api/installed-by"
+	releaseLabelUpdatedBy   = "catalog-api/updated-by"
+)
+
+var invalidLabelValueChars = regexp.MustCompile(`[^A-Za-z0-9_.-]+`)
+
+// installReleaseLabels are the labels of a new release, upgrades keep the labels they do not set
+func installReleaseLabels(c *fiber.Ctx, repoName string, chartName string) map[string]string {
+	user := releaseLabelUser(c)
+	return map[string]string{
+		releaseLabelRepo:        labelValue(repoName),
+		releaseLabelChart:       labelValue(chartName),
+		releaseLabelInstalledBy: user,
+		releaseLabelUpdatedBy:   user,
+	}
+}
+
+func upgradeReleaseLabels(c *fiber.Ctx, repoName string, chartName string) map[string]string {
+	return map[string]string{
+		releaseLabelRepo:      labelValue(repoName),
+		releaseLabelChart:     labelValue(chartName),
+		releaseLabelUpdatedBy: releaseLabelUser(c),
+	}
+}
+
+// releaseSourceRepo returns the repository recorded on the release, empty for releases not deployed by the api
+func releaseSourceRepo(r *release.Release) string {
+	if r == nil {
+		return ""
+	}
+	return r.Labels[releaseLabelRepo]
+}
+
+// setReleaseSource fills the recorded source of the release into the element
+func setReleaseSource(element *releaseElement, r *release.Release) {
+	element.Repo = r.Labels[releaseLabelRepo]
+	if element.Repo != "" {
+		chartName := r.Labels[releaseLabelChart]
+		if chartName == "" {
+			chartName = element.Chart
+		}
+		element.ChartRef = element.Repo + "/" + chartName
+	}
+	element.InstalledBy = r.Labels[releaseLabelInstalledBy]
+	element.UpdatedBy = r.Labels[releaseLabelUpdatedBy]
+}
+
+func releaseLabelUser(c *fiber.Ctx) string {
+	userAuthId, _ := common.UserClaims(c)["userAuthId"].(string)
+	return labelValue(userAuthId)
+}
+
+// labelValue turns a value into a valid label value, the storage drivers keep the release labels as kube labels
+func labelValue(value string) string {
+	if len(validation.IsValidLabelValue(value)) == 0 {
+		return value
+	}
+	value = invalidLabelValueChars.ReplaceAllString(value, "_")
+	if len(value) > validation.LabelValueMaxLength {
+		value = value[:validation.LabelValueMaxLength]
+	}
+	return strings.Trim(value, "_.-")
+}
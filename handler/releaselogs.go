@@ -0,0 +1,304 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"io"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// logs of a container are cut at this size unless followed
+	maxPodLogBytes = 1 << 20
+	// comment lines keep idle log streams open and detect closed connections
+	logStreamHeartbeat = 15 * time.Second
+)
+
+type podLogTarget struct {
+	Pod       string
+	Namespace string
+	Container string
+}
+
+type podLogElement struct {
+	Pod       string `json:"pod"`
+	Namespace string `json:"namespace"`
+	Container string `json:"container"`
+	Logs      string `json:"logs"`
+	Error     string `json:"error,omitempty"`
+}
+
+type podLogLine struct {
+	Pod       string `json:"pod"`
+	Container string `json:"container"`
+	Line      string `json:"line,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// GetReleaseLogs
+// @Summary Get Release Pod Logs
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Produce text/event-stream
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/logs [Get]
+func GetReleaseLogs(c *fiber.Ctx) error {
+	logOptions, follow, err := podLogOptionsCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	rel, err := action.NewGet(actionConfig).Run(c.Params("release"))
+	if err != nil {
+		if errors.Is(err, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, err)
+	}
+	// the pods are read with the token of the release cluster and namespace
+	clientSet, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	mapper, err := actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	pods, err := releasePods(c.Context(), clientSet, mapper, rel)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	targets := podLogTargets(pods, c.Query("pod"), c.Query("container"))
+
+	if follow {
+		return streamPodLogs(c, clientSet, targets, logOptions)
+	}
+
+	return common.RespOK(c, readPodLogs(c.Context(), clientSet, targets, logOptions))
+}
+
+// readPodLogs reads the logs of each container, a container whose logs cannot be read is reported with the error
+func readPodLogs(ctx context.Context, clientSet kubernetes.Interface, targets []podLogTarget, logOptions *v1.PodLogOptions) []podLogElement {
+	elements := make([]podLogElement, 0, len(targets))
+	for _, t := range targets {
+		element := podLogElement{Pod: t.Pod, Namespace: t.Namespace, Container: t.Container}
+		opts := *logOptions
+		opts.Container = t.Container
+		data, err := clientSet.CoreV1().Pods(t.Namespace).GetLogs(t.Pod, &opts).DoRaw(ctx)
+		if err != nil {
+			element.Error = err.Error()
+		} else {
+			element.Logs = string(data)
+		}
+		elements = append(elements, element)
+	}
+	return elements
+}
+
+// podLogOptionsCheck parses since (duration) or sinceTime (RFC3339), tailLines and follow
+func podLogOptionsCheck(c *fiber.Ctx) (*v1.PodLogOptions, bool, error) {
+	opts := &v1.PodLogOptions{Timestamps: c.QueryBool("timestamps", false)}
+
+	follow, err := strconv.ParseBool(c.Query("follow", "false"))
+	if err != nil {
+		return nil, false, fmt.Errorf(common.LOG_OPTION_INVALID)
+	}
+
+	since, sinceTime := c.Query("since"), c.Query("sinceTime")
+	switch {
+	case since != "" && sinceTime != "":
+		return nil, false, fmt.Errorf(common.LOG_OPTION_INVALID)
+	case since != "":
+		d, err := time.ParseDuration(since)
+		if err != nil || d < time.Second {
+			return nil, false, fmt.Errorf(common.LOG_OPTION_INVALID)
+		}
+		seconds := int64(d.Seconds())
+		opts.SinceSeconds = &seconds
+	case sinceTime != "":
+		t, err := time.Parse(time.RFC3339, sinceTime)
+		if err != nil {
+			return nil, false, fmt.Errorf(common.LOG_OPTION_INVALID)
+		}
+		opts.SinceTime = &metav1.Time{Time: t}
+	}
+
+	if value := c.Query("tailLines"); value != "" {
+		tailLines, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || tailLines < 0 {
+			return nil, false, fmt.Errorf(common.LOG_OPTION_INVALID)
+		}
+		opts.TailLines = &tailLines
+	}
+	if !follow {
+		limit := int64(maxPodLogBytes)
+		opts.LimitBytes = &limit
+	}
+	opts.Follow = follow
+	return opts, follow, nil
+}
+
+// podLogTargets returns the containers to read the logs of, the app containers of every pod without a container,
+// a named container is also looked up in the init and ephemeral containers
+func podLogTargets(pods []v1.Pod, podName string, containerName string) []podLogTarget {
+	targets := make([]podLogTarget, 0)
+	for _, pod := range pods {
+		if podName != "" && pod.Name != podName {
+			continue
+		}
+		if containerName == "" {
+			for _, container := range pod.Spec.Containers {
+				targets = append(targets, podLogTarget{Pod: pod.Name, Namespace: pod.Namespace, Container: container.Name})
+			}
+			continue
+		}
+		for _, name := range podContainerNames(pod) {
+			if name == containerName {
+				targets = append(targets, podLogTarget{Pod: pod.Name, Namespace: pod.Namespace, Container: name})
+			}
+		}
+	}
+	return targets
+}
+
+// podContainerNames returns the init, app and ephemeral containers of the pod in the order they run
+func podContainerNames(pod v1.Pod) []string {
+	names := make([]string, 0, len(pod.Spec.InitContainers)+len(pod.Spec.Containers)+len(pod.Spec.EphemeralContainers))
+	for _, container := range pod.Spec.InitContainers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.Containers {
+		names = append(names, container.Name)
+	}
+	for _, container := range pod.Spec.EphemeralContainers {
+		names = append(names, container.Name)
+	}
+	return names
+}
+
+// streamPodLogs follows the logs of the containers as server-sent events, a log event for each line,
+// an error event for a container whose logs cannot be read and an end event when every stream ended
+func streamPodLogs(c *fiber.Ctx, clientSet kubernetes.Interface, targets []podLogTarget, logOptions *v1.PodLogOptions) error {
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		lines := make(chan podLogLine)
+		var wg sync.WaitGroup
+		for _, t := range targets {
+			wg.Add(1)
+			go func(t podLogTarget) {
+				defer wg.Done()
+				followContainerLogs(ctx, clientSet, t, logOptions, lines)
+			}(t)
+		}
+		go func() {
+			wg.Wait()
+			close(lines)
+		}()
+
+		heartbeat := time.NewTicker(logStreamHeartbeat)
+		defer heartbeat.Stop()
+		for {
+			select {
+			case line, ok := <-lines:
+				if !ok {
+					_ = writeLogEvent(w, "end", nil)
+					return
+				}
+				event := "log"
+				if line.Error != "" {
+					event = "error"
+				}
+				if err := writeLogEvent(w, event, line); err != nil {
+					log.Infof("streamPodLogs:: client disconnected :: %v", err)
+					return
+				}
+			case <-heartbeat.C:
+				if _, err := w.WriteString(": ping\n\n"); err != nil || w.Flush() != nil {
+					log.Infof("streamPodLogs:: client disconnected")
+					return
+				}
+			}
+		}
+	})
+	return nil
+}
+
+func followContainerLogs(ctx context.Context, clientSet kubernetes.Interface, t podLogTarget, logOptions *v1.PodLogOptions, lines chan<- podLogLine) {
+	send := func(line podLogLine) bool {
+		select {
+		case lines <- line:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	opts := *logOptions
+	opts.Container = t.Container
+	stream, err := clientSet.CoreV1().Pods(t.Namespace).GetLogs(t.Pod, &opts).Stream(ctx)
+	if err != nil {
+		send(podLogLine{Pod: t.Pod, Container: t.Container, Error: err.Error()})
+		return
+	}
+	defer stream.Close()
+
+	reader := bufio.NewReader(stream)
+	for {
+		text, err := reader.ReadString('\n')
+		if len(text) > 0 && !send(podLogLine{Pod: t.Pod, Container: t.Container, Line: trimLineEnd(text)}) {
+			return
+		}
+		if err != nil {
+			if err != io.EOF && ctx.Err() == nil {
+				send(podLogLine{Pod: t.Pod, Container: t.Container, Error: err.Error()})
+			}
+			return
+		}
+	}
+}
+
+func writeLogEvent(w *bufio.Writer, event string, data interface{}) error {
+	payload := []byte("{}")
+	if data != nil {
+		var err error
+		if payload, err = json.Marshal(data); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return err
+	}
+	return w.Flush()
+}
+
+func trimLineEnd(line string) string {
+	for len(line) > 0 && (line[len(line)-1] == '\n' || line[len(line)-1] == '\r') {
+		line = line[:len(line)-1]
+	}
+	return line
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"fmt"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"time"
+)
+
+const (
+	releaseActionInstall  = "install"
+	releaseActionUpgrade  = "upgrade"
+	releaseActionRollback = "rollback"
+)
+
+// releaseOptions are the helm flags of install, upgrade and rollback, each action takes the options it supports
+type releaseOptions struct {
+	ReuseValues   bool   `json:"reuse_values"`
+	ResetValues   bool   `json:"reset_values"`
+	Wait          bool   `json:"wait"`
+	WaitForJobs   bool   `json:"wait_for_jobs"`
+	Atomic        bool   `json:"atomic"`
+	Timeout       string `json:"timeout"`
+	Force         bool   `json:"force"`
+	CleanupOnFail bool   `json:"cleanup_on_fail"`
+	MaxHistory    int    `json:"max_history"`
+	DisableHooks  bool   `json:"disable_hooks"`
+	SubNotes      bool   `json:"sub_notes"`
+
+	timeout time.Duration
+}
+
+// checkReleaseOptions validates the options for the action and parses the timeout, defaultTimeout without one
+func checkReleaseOptions(opts *releaseOptions, releaseAction string) (*releaseOptions, error) {
+	if opts == nil {
+		opts = &releaseOptions{}
+	}
+
+	timeout := opts.Timeout
+	if timeout == "" {
+		timeout = defaultTimeout
+	}
+	d, err := time.ParseDuration(timeout)
+	if err != nil || d <= 0 {
+		return nil, fmt.Errorf(common.RELEASE_OPTION_INVALID)
+	}
+	opts.timeout = d
+
+	switch {
+	case opts.ReuseValues && opts.ResetValues,
+		opts.WaitForJobs && !opts.Wait && !opts.Atomic,
+		opts.MaxHistory < 0:
+		return nil, fmt.Errorf(common.RELEASE_OPTION_INVALID)
+	}
+
+	switch releaseAction {
+	case releaseActionInstall:
+		if opts.ReuseValues || opts.ResetValues || opts.CleanupOnFail || opts.MaxHistory > 0 {
+			return nil, fmt.Errorf(common.RELEASE_OPTION_INVALID)
+		}
+	case releaseActionRollback:
+		if opts.ReuseValues || opts.ResetValues || opts.Atomic || opts.SubNotes {
+			return nil, fmt.Errorf(common.RELEASE_OPTION_INVALID)
+		}
+	}
+	return opts, nil
+}
+
+func applyInstallOptions(client *action.Install, opts *releaseOptions) {
+	client.Wait = opts.Wait || opts.Atomic
+	client.WaitForJobs = opts.WaitForJobs
+	client.Atomic = opts.Atomic
+	client.Timeout = opts.timeout
+	client.Force = opts.Force
+	client.DisableHooks = opts.DisableHooks
+	client.SubNotes = opts.SubNotes
+}
+
+func applyUpgradeOptions(client *action.Upgrade, opts *releaseOptions) {
+	client.ReuseValues = opts.ReuseValues
+	client.ResetValues = opts.ResetValues
+	client.Wait = opts.Wait || opts.Atomic
+	client.WaitForJobs = opts.WaitForJobs
+	client.Atomic = opts.Atomic
+	client.Timeout = opts.timeout
+	client.Force = opts.Force
+	client.CleanupOnFail = opts.CleanupOnFail
+	client.MaxHistory = opts.MaxHistory
+	client.DisableHooks = opts.DisableHooks
+	client.SubNotes = opts.SubNotes
+}
+
+func applyRollbackOptions(client *action.Rollback, opts *releaseOptions) {
+	client.Wait = opts.Wait
+	client.WaitForJobs = opts.WaitForJobs
+	client.Timeout = opts.timeout
+	client.Force = opts.Force
+	client.CleanupOnFail = opts.CleanupOnFail
+	client.MaxHistory = opts.MaxHistory
+	client.DisableHooks = opts.DisableHooks
+}
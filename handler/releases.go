@@ -11,8 +11,6 @@ import (
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/chartutil"
-	"helm.sh/helm/v3/pkg/downloader"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/releaseutil"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -25,21 +23,28 @@ import (
 var defaultTimeout = "5m0s"
 
 type releaseElement struct {
-	Name         string      `json:"name"`
-	Namespace    string      `json:"namespace"`
-	Repo         string      `json:"repo"`
-	Revision     string      `json:"revision"`
-	Updated      string      `json:"updated"`
-	Status       string      `json:"status"`
-	Chart        string      `json:"chart"`
-	ChartVersion string      `json:"chart_version"`
-	AppVersion   string      `json:"app_version"`
-	Home         string      `json:"home"`
-	Icon         string      `json:"icon"`
-	Notes        string      `json:"notes"`
-	Values       string      `json:"values"`
-	Resources    interface{} `json:"resources"`
-	Manifest     string      `json:"manifest"`
+	Name         string               `json:"name"`
+	Namespace    string               `json:"namespace"`
+	Repo         string               `json:"repo"`
+	Revision     string               `json:"revision"`
+	Updated      string               `json:"updated"`
+	Status       string               `json:"status"`
+	Chart        string               `json:"chart"`
+	ChartVersion string               `json:"chart_version"`
+	AppVersion   string               `json:"app_version"`
+	Home         string               `json:"home"`
+	Icon         string               `json:"icon"`
+	Notes        string               `json:"notes"`
+	Values       string               `json:"values"`
+	Resources    interface{}          `json:"resources"`
+	Manifest     string               `json:"manifest"`
+	Labels       map[string]string    `json:"labels,omitempty"`
+	ChartRef     string               `json:"chart_ref,omitempty"`
+	InstalledBy  string               `json:"installed_by,omitempty"`
+	UpdatedBy    string               `json:"updated_by,omitempty"`
+	Options      *releaseOptions      `json:"options,omitempty"`
+	Tests        []releaseTestElement `json:"tests,omitempty"`
+	Health       *releaseHealth       `json:"health,omitempty"`
 }
 
 type releaseInfo struct {
@@ -65,16 +70,22 @@ func ListReleases(c *fiber.Ctx) error {
 		return common.RespErr(c, err)
 	}
 
+	rlf, err := releaseListFilterCheck(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	// the health of each release is read from the cluster, only when asked for
+	withHealth, err := strconv.ParseBool(c.Query("health", "false"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
 	actionConfig, err := common.ActionConfigInit(c)
 	if err != nil {
 		return common.RespErr(c, err)
 	}
 
-	client := action.NewList(actionConfig)
-	client.All = true
-	client.ByDate = true
-	client.SortReverse = true
-	results, err := client.Run()
+	results, err := rlf.run(actionConfig)
 	if err != nil {
 		return common.RespErr(c, err)
 	}
@@ -83,9 +94,29 @@ func ListReleases(c *fiber.Ctx) error {
 	for _, r := range results {
 		elements = append(elements, constructReleaseElement(r, false))
 	}
+	if !withHealth {
+		itemCount, resultData := ResourceListProcessing(elements, lse)
+		return common.ListRespOK(c, itemCount, resultData)
+	}
 
-	itemCount, resultData := ResourceListProcessing(elements, lse)
-	return common.ListRespOK(c, itemCount, resultData)
+	// the health is read only for the releases of the page returned
+	itemCount, page := resourceListPage(elements, lse)
+	byName := make(map[string]*release.Release, len(results))
+	for _, r := range results {
+		byName[r.Namespace+"/"+r.Name] = r
+	}
+	pageReleases := make([]*release.Release, 0, len(page))
+	for _, item := range page {
+		element := item.(releaseElement)
+		pageReleases = append(pageReleases, byName[element.Namespace+"/"+element.Name])
+	}
+	healths := listReleaseHealth(c.Context(), actionConfig, pageReleases)
+	for i, item := range page {
+		element := item.(releaseElement)
+		element.Health = healths[i]
+		page[i] = element
+	}
+	return common.ListRespOK(c, itemCount, projectResourceList(page, lse))
 }
 
 // GetReleaseInfo
@@ -117,6 +148,7 @@ func GetReleaseInfo(c *fiber.Ctx) error {
 	if err != nil {
 		return common.RespErr(c, err)
 	}
+	releaseElement.Health = releaseHealthOf(c.Context(), actionConfig, results)
 
 	return common.RespOK(c, releaseElement)
 }
@@ -149,7 +181,7 @@ func InstallRelease(c *fiber.Ctx) error {
 
 	rel, err := runInstall(c, newRelease, preview)
 	if err != nil {
-		return common.RespErr(c, err)
+		return respReleaseErr(c, rel, err)
 	}
 
 	releaseElement, err := constructReleaseInfoElement(rel, userDefined)
@@ -181,18 +213,35 @@ func UpgradeRelease(c *fiber.Ctx) error {
 	if err != nil {
 		return common.RespErr(c, err)
 	}
+	opts, err := checkReleaseOptions(upgradeRelease.Options, releaseActionUpgrade)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
 
 	actionConfig, err := common.ActionConfigInit(c)
 	if err != nil {
 		return common.RespErr(c, err)
 	}
 
+	// the repository recorded on install is used when the upgrade does not name one
+	if upgradeRelease.Repo == "" {
+		current, err := action.NewGet(actionConfig).Run(upgradeRelease.Name)
+		if err != nil {
+			if errors.Is(err, driver.ErrReleaseNotFound) {
+				return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+			}
+			return common.RespErr(c, err)
+		}
+		upgradeRelease.Repo = releaseSourceRepo(current)
+	}
+
 	client := action.NewUpgrade(actionConfig)
 	client.Namespace = upgradeRelease.Namespace
 	client.Version = upgradeRelease.ChartVersion
+	client.Labels = upgradeReleaseLabels(c, upgradeRelease.Repo, upgradeRelease.Chart)
+	applyUpgradeOptions(client, opts)
 
-	aimChart := fmt.Sprintf("%s/%s", upgradeRelease.Repo, upgradeRelease.Chart)
-	cp, err := client.ChartPathOptions.LocateChart(aimChart, settings)
+	cp, _, err := locateRepoChart(&client.ChartPathOptions, upgradeRelease.Repo, upgradeRelease.Chart)
 	if err != nil {
 		return common.RespErr(c, err)
 	}
@@ -203,14 +252,19 @@ func UpgradeRelease(c *fiber.Ctx) error {
 	}
 
 	if req := chartRequested.Metadata.Dependencies; req != nil {
-		if err := action.CheckDependencies(chartRequested, req); err != nil {
+		chartRequested, err = resolveChartDependencies(chartRequested)
+		if err != nil {
 			return common.RespErr(c, err)
 		}
 	}
 
-	_, err = client.Run(upgradeRelease.Name, chartRequested, vals)
+	if err := checkChartValues(chartRequested, vals); err != nil {
+		return respValuesErr(c, err)
+	}
+
+	rel, err := client.Run(upgradeRelease.Name, chartRequested, vals)
 	if err != nil {
-		return common.RespErr(c, err)
+		return respReleaseErr(c, rel, err)
 	}
 
 	return common.RespOK(c, nil)
@@ -231,6 +285,18 @@ func RollbackRelease(c *fiber.Ctx) error {
 		return common.RespErr(c, fmt.Errorf(common.REVISION_NUMBER_INVALID))
 	}
 
+	// the options body is optional for rollbacks
+	rollbackOptions := new(releaseOptions)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(rollbackOptions); err != nil {
+			return common.RespErr(c, err)
+		}
+	}
+	opts, err := checkReleaseOptions(rollbackOptions, releaseActionRollback)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
 	actionConfig, err := common.ActionConfigInit(c)
 	if err != nil {
 		return common.RespErr(c, err)
@@ -238,6 +304,7 @@ func RollbackRelease(c *fiber.Ctx) error {
 
 	client := action.NewRollback(actionConfig)
 	client.Version = revision
+	applyRollbackOptions(client, opts)
 
 	err = client.Run(name)
 	if err != nil {
@@ -343,6 +410,10 @@ func runInstall(c *fiber.Ctx, r *releaseElement, justTemplate bool) (*release.Re
 	if err != nil {
 		return nil, err
 	}
+	opts, err := checkReleaseOptions(r.Options, releaseActionInstall)
+	if err != nil {
+		return nil, err
+	}
 
 	actionConfig, err := common.ActionConfigInit(c)
 	if err != nil {
@@ -350,18 +421,18 @@ func runInstall(c *fiber.Ctx, r *releaseElement, justTemplate bool) (*release.Re
 	}
 
 	client := action.NewInstall(actionConfig)
+	applyInstallOptions(client, opts)
 	client.ReleaseName = r.Name
 	client.Namespace = r.Namespace
 	client.Version = r.ChartVersion
+	client.Labels = installReleaseLabels(c, r.Repo, r.Chart)
 
 	if justTemplate {
 		client.DryRunOption = "true"
 		client.DryRun = true
 	}
 
-	aimChart := fmt.Sprintf("%s/%s", r.Repo, r.Chart)
-
-	cp, err := client.ChartPathOptions.LocateChart(aimChart, settings)
+	cp, _, err := locateRepoChart(&client.ChartPathOptions, r.Repo, r.Chart)
 	if err != nil {
 		return nil, err
 	}
@@ -377,39 +448,30 @@ func runInstall(c *fiber.Ctx, r *releaseElement, justTemplate bool) (*release.Re
 	}
 
 	if req := chartRequested.Metadata.Dependencies; req != nil {
-		// If CheckDependencies returns an error, we have unfulfilled dependencies.
-		// As of Helm 2.4.0, this is treated as a stopping condition:
-		// https://github.com/helm/helm/issues/2209
-		if err = action.CheckDependencies(chartRequested, req); err != nil {
-			if client.DependencyUpdate {
-				man := &downloader.Manager{
-					ChartPath:        cp,
-					Keyring:          client.ChartPathOptions.Keyring,
-					SkipUpdate:       false,
-					Getters:          getter.All(settings),
-					RepositoryConfig: settings.RepositoryConfig,
-					RepositoryCache:  settings.RepositoryCache,
-				}
-				if err = man.Update(); err != nil {
-					return nil, err
-				}
-			} else {
-				return nil, err
-			}
+		// missing dependencies are resolved from the configured repositories
+		chartRequested, err = resolveChartDependencies(chartRequested)
+		if err != nil {
+			return nil, err
 		}
 	}
 
+	if err := checkChartValues(chartRequested, vals); err != nil {
+		return nil, err
+	}
+
 	rel, err := client.Run(chartRequested, vals)
 	if err != nil {
 		if rel != nil {
 			log.Errorf("installation failed:: namespace:%v, name:%v, status:%v, error:%v", rel.Namespace, rel.Name, rel.Info.Status.String(), err)
-			if rel.Info.Status == release.StatusFailed {
+			// atomic installs are uninstalled by helm itself
+			if rel.Info.Status == release.StatusFailed && !client.Atomic {
 				//uninstall release if installation failed (StatusFailed)
 				log.Infof("uninstall release:: namespace:%v, name:%v", rel.Namespace, rel.Name)
 				_ = runUninstall(actionConfig, rel.Name)
 			}
 		}
-		return nil, err
+		// the failed release is returned for its hooks
+		return rel, err
 	}
 
 	log.Infof("installed release status:: namespace:%v, name:%v, preview:%v, status:%v, desc:%v",
@@ -448,7 +510,10 @@ func constructReleaseElement(r *release.Release, showStatus bool) releaseElement
 		AppVersion:   procReplaceEmpty(r.Chart.Metadata.AppVersion),
 		Icon:         r.Chart.Metadata.Icon,
 		Resources:    make([]string, 0),
+		Labels:       r.Labels,
+		Tests:        releaseLastTests(r),
 	}
+	setReleaseSource(&element, r)
 	if showStatus {
 		element.Notes = r.Info.Notes
 	}
@@ -481,7 +546,10 @@ func constructReleaseInfoElement(r *release.Release, userDefined bool) (releaseE
 		Values:       values,
 		Resources:    GetResources(r.Manifest),
 		Manifest:     r.Manifest,
+		Labels:       r.Labels,
+		Tests:        releaseLastTests(r),
 	}
+	setReleaseSource(&element, r)
 
 	t := "-"
 	if tspb := r.Info.LastDeployed; !tspb.IsZero() {
@@ -550,7 +618,7 @@ func formatAppVersion(c *chart.Chart) string {
 	return c.AppVersion()
 }
 func GetReleaseOld(c *fiber.Ctx) error {
-	infos := []string{"hooks", "manifest", "notes", "values"}
+	infos := []string{"manifest", "notes", "values"}
 
 	name := c.Params("release")
 	info := c.Query("info")
@@ -564,7 +632,7 @@ func GetReleaseOld(c *fiber.Ctx) error {
 		infoMap[i] = true
 	}
 	if _, ok := infoMap[info]; !ok {
-		return common.RespErr(c, fmt.Errorf("bad info %s, release info only support hooks/manifest/notes/values", info))
+		return common.RespErr(c, fmt.Errorf("bad info %s, release info only support manifest/notes/values", info))
 	}
 
 	actionConfig, err := common.ActionConfigInit(c)
@@ -605,14 +673,8 @@ func GetReleaseOld(c *fiber.Ctx) error {
 		return common.RespErr(c, err)
 	}
 
-	// TODO: support all
-	if info == "hooks" {
-		if len(results.Hooks) < 1 {
-			return common.RespOK(c, []*release.Hook{})
-		}
-		return common.RespOK(c, results.Hooks)
-
-	} else if info == "manifest" {
+	// hooks are served by GetReleaseHooks
+	if info == "manifest" {
 		return common.RespOK(c, results.Manifest)
 	} else if info == "notes" {
 		return common.RespOK(c, results.Info.Notes)
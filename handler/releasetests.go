@@ -0,0 +1,182 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"strconv"
+	"time"
+)
+
+const releaseActionTest = "test"
+
+type releaseTestResult struct {
+	Name      string               `json:"name"`
+	Namespace string               `json:"namespace"`
+	Revision  int                  `json:"revision"`
+	Passed    bool                 `json:"passed"`
+	Tests     []releaseTestElement `json:"tests"`
+}
+
+type releaseTestElement struct {
+	Name        string          `json:"name"`
+	Kind        string          `json:"kind"`
+	Phase       string          `json:"phase"`
+	StartedAt   string          `json:"started_at"`
+	CompletedAt string          `json:"completed_at"`
+	Logs        []podLogElement `json:"logs,omitempty"`
+	LogError    string          `json:"log_error,omitempty"`
+}
+
+// RunReleaseTests
+// @Summary Run Release Tests
+// @Tags Releases
+// @Accept json
+// @Produce json
+// @Router /api/clusters/:clusterId/namespaces/:namespace/releases/:release/tests [Post]
+func RunReleaseTests(c *fiber.Ctx) error {
+	name := c.Params("release")
+	opts, err := checkReleaseOptions(&releaseOptions{Timeout: c.Query("timeout")}, releaseActionTest)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	logs, err := strconv.ParseBool(c.Query("logs", "true"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	actionConfig, err := common.ActionConfigInit(c)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	// include=name,name runs only the named tests, exclude=name,name skips them
+	client := action.NewReleaseTesting(actionConfig)
+	client.Namespace = c.Params("namespace")
+	client.Timeout = opts.timeout
+	client.Filters[action.IncludeNameFilter] = splitListParam(c.Query("include"), ",")
+	client.Filters[action.ExcludeNameFilter] = splitListParam(c.Query("exclude"), ",")
+
+	log.Infof("Run release tests :: namespace: %s, name: %s, timeout: %v", client.Namespace, name, client.Timeout)
+	rel, runErr := client.Run(name)
+	if rel == nil {
+		if errors.Is(runErr, driver.ErrReleaseNotFound) {
+			return common.RespErr(c, fmt.Errorf(common.RELEASE_NOT_FOUND))
+		}
+		return common.RespErr(c, runErr)
+	}
+
+	result := releaseTestResult{
+		Name:      rel.Name,
+		Namespace: rel.Namespace,
+		Revision:  rel.Version,
+		Passed:    runErr == nil,
+		Tests:     make([]releaseTestElement, 0),
+	}
+	for _, h := range releaseTestHooks(rel) {
+		if !testHookSelected(client.Filters, h.Name) {
+			continue
+		}
+		element := constructReleaseTestElement(h)
+		if logs && h.Kind == "Pod" {
+			element.Logs, err = testPodLogs(actionConfig, client.Namespace, h.Name)
+			if err != nil {
+				// pods deleted by the hook-succeeded policy have no logs left
+				element.LogError = err.Error()
+			}
+		}
+		result.Tests = append(result.Tests, element)
+	}
+
+	if runErr != nil {
+		log.Errorf("RunReleaseTests:: namespace: %s, name: %s :: %v", client.Namespace, name, runErr)
+		return common.RespErrItems(c, fmt.Errorf(common.RELEASE_TEST_FAILED), result)
+	}
+	return common.RespOK(c, result)
+}
+
+// releaseTestHooks returns the test hooks of the release in the order they run
+func releaseTestHooks(rel *release.Release) []*release.Hook {
+	hooks := make([]*release.Hook, 0)
+	for _, h := range sortedReleaseHooks(rel.Hooks) {
+		if hookHasEvent(h, release.HookTest.String()) {
+			hooks = append(hooks, h)
+		}
+	}
+	return hooks
+}
+
+// releaseLastTests returns the last run of the release tests, empty when they never ran
+func releaseLastTests(rel *release.Release) []releaseTestElement {
+	tests := make([]releaseTestElement, 0)
+	for _, h := range releaseTestHooks(rel) {
+		if h.LastRun.StartedAt.IsZero() {
+			continue
+		}
+		tests = append(tests, constructReleaseTestElement(h))
+	}
+	return tests
+}
+
+func constructReleaseTestElement(h *release.Hook) releaseTestElement {
+	return releaseTestElement{
+		Name:        h.Name,
+		Kind:        h.Kind,
+		Phase:       procReplaceEmpty(h.LastRun.Phase.String()),
+		StartedAt:   formatDateTime(h.LastRun.StartedAt.Time),
+		CompletedAt: formatDateTime(h.LastRun.CompletedAt.Time),
+	}
+}
+
+func formatDateTime(t time.Time) string {
+	if t.IsZero() {
+		return "-"
+	}
+	return t.Format(time.DateTime)
+}
+
+func testHookSelected(filters map[string][]string, name string) bool {
+	for _, n := range filters[action.ExcludeNameFilter] {
+		if n == name {
+			return false
+		}
+	}
+	if len(filters[action.IncludeNameFilter]) == 0 {
+		return true
+	}
+	for _, n := range filters[action.IncludeNameFilter] {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// testPodLogs reads the logs of the init, app and ephemeral containers of the test pod, each cut at the size of the release logs
+func testPodLogs(actionConfig *action.Configuration, namespace string, podName string) ([]podLogElement, error) {
+	clientSet, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		return nil, err
+	}
+	ctx := context.Background()
+	pod, err := clientSet.CoreV1().Pods(namespace).Get(ctx, podName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	limit := int64(maxPodLogBytes)
+	// a failing test often fails in an init container, so every container of the pod is read
+	targets := make([]podLogTarget, 0)
+	for _, name := range podContainerNames(*pod) {
+		targets = append(targets, podLogTarget{Pod: pod.Name, Namespace: pod.Namespace, Container: name})
+	}
+	return readPodLogs(ctx, clientSet, targets, &v1.PodLogOptions{LimitBytes: &limit}), nil
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/pem"
@@ -12,13 +13,13 @@ import (
 	"go-api/common"
 	"go-api/config"
 	"helm.sh/helm/v3/cmd/helm/search"
-	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/helmpath"
 	"helm.sh/helm/v3/pkg/repo"
-	"net/http"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sigs.k8s.io/yaml"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -38,11 +39,30 @@ type repositoryElement struct {
 type addRepositoryElement struct {
 	Name     string `json:"name"`
 	URL      string `json:"url"`
-	Username string `json:"username"`
-	Password string `json:"password"`
-	CaBase64 string `json:"ca_base64"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	CaBase64 string `json:"ca_base64,omitempty"`
 }
 
+type repositoryCatalog struct {
+	APIVersion   string                  `json:"apiVersion"`
+	Generated    time.Time               `json:"generated"`
+	Repositories []*addRepositoryElement `json:"repositories"`
+}
+
+type repoImportResult struct {
+	Name          string `json:"name"`
+	URL           string `json:"url"`
+	ResultCode    string `json:"resultCode"`
+	ResultMessage string `json:"resultMessage"`
+}
+
+const (
+	repoCatalogAPIVersion = "v1"
+	repoImportModeMerge   = "merge"
+	repoImportModeReplace = "replace"
+)
+
 func addRepoVaildCheck(newRepo *addRepositoryElement) error {
 	if newRepo.Name == "" || newRepo.URL == "" {
 		return fmt.Errorf(common.REPO_NAME_URL_REQUIRED)
@@ -56,6 +76,9 @@ func addRepoVaildCheck(newRepo *addRepositoryElement) error {
 	if strings.Contains(newRepo.Name, "/") {
 		return fmt.Errorf(common.REPO_NAME_CONTAINS_SC)
 	}
+	if isHostedRepo(newRepo.Name) {
+		return fmt.Errorf(common.REPO_NAME_RESERVED)
+	}
 	// Block deprecated repos
 	for oldURL, newURL := range deprecatedRepos {
 		if strings.Contains(newRepo.URL, oldURL) {
@@ -66,7 +89,8 @@ func addRepoVaildCheck(newRepo *addRepositoryElement) error {
 	if (newRepo.Username != "" && newRepo.Password == "") || (newRepo.Username == "" && newRepo.Password != "") {
 		return errors.New(common.REPO_USERNAME_PASSWD_REQUIRED)
 	}
-	return nil
+
+	return checkRepoURLPolicy(newRepo.URL)
 }
 
 // AddRepo
@@ -81,14 +105,6 @@ func AddRepo(c *fiber.Ctx) error {
 	if err := c.BodyParser(newRepo); err != nil {
 		return common.RespErr(c, err)
 	}
-	if err := addRepoVaildCheck(newRepo); err != nil {
-		return common.RespErr(c, err)
-	}
-
-	log.Infof("Add repo :: name: %s, url: %s", newRepo.Name, newRepo.URL)
-	if err := getRepoConnectionStatus(newRepo.URL); err != nil {
-		return common.RespErr(c, err)
-	}
 
 	// Ensure the file directory exists as it is required for file locking
 	err := os.MkdirAll(filepath.Dir(repoFile), os.ModePerm)
@@ -108,6 +124,27 @@ func AddRepo(c *fiber.Ctx) error {
 		return common.RespErr(c, fmt.Errorf(common.REPO_FAILED_LOADING_FILE))
 	}
 
+	repoEntry, err := addRepoEntry(f, newRepo, false)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	if err := f.WriteFile(repoFile, 0600); err != nil {
+		log.Errorf("Write Repofile ::  %s", err.Error())
+		_ = RemoveFile(repoEntry.CAFile)
+		return common.RespErr(c, err)
+	}
+
+	return common.RespOK(c, nil)
+}
+
+// addRepoEntry validates the new repository, downloads its index file and adds it to the repositories file,
+// a repository of the same name configured differently is replaced only with overwrite
+func addRepoEntry(f *repo.File, newRepo *addRepositoryElement, overwrite bool) (*repo.Entry, error) {
+	if err := addRepoVaildCheck(newRepo); err != nil {
+		return nil, err
+	}
+
 	repoEntry := repo.Entry{
 		Name:     newRepo.Name,
 		URL:      newRepo.URL,
@@ -116,12 +153,19 @@ func AddRepo(c *fiber.Ctx) error {
 	}
 
 	if f.Has(newRepo.Name) {
-		existing := f.Get(newRepo.Name)
-		if repoEntry != *existing {
-			return common.RespErr(c, errors.Errorf(common.REPO_NAME_ALREADY_EXISTS))
+		if sameRepoEntry(f.Get(newRepo.Name), newRepo) {
+			// The add is idempotent so do nothing
+			return nil, errors.Errorf(common.REPO_SAME_CONF_ALREADY_EXISTS)
+		}
+		if !overwrite {
+			return nil, errors.Errorf(common.REPO_NAME_ALREADY_EXISTS)
 		}
-		// The add is idempotent so do nothing
-		return common.RespErr(c, errors.Errorf(common.REPO_SAME_CONF_ALREADY_EXISTS))
+		log.Infof("Update repo :: name: %s, url: %s", newRepo.Name, newRepo.URL)
+	} else {
+		log.Infof("Add repo :: name: %s, url: %s", newRepo.Name, newRepo.URL)
+	}
+	if err := getRepoConnectionStatus(newRepo.URL); err != nil {
+		return nil, err
 	}
 
 	// save ca.crt
@@ -130,19 +174,19 @@ func AddRepo(c *fiber.Ctx) error {
 		caFile := fmt.Sprintf("%v_%v.crt", newRepo.Name, generatingId())
 		caFilePath = filepath.Join(config.Env.HelmRepoCA, caFile)
 		if err := os.MkdirAll(config.Env.HelmRepoCA, os.ModePerm); err != nil && !os.IsExist(err) {
-			return common.RespErr(c, err)
+			return nil, err
 		}
 		if err := saveRepoCaFile(caFilePath, newRepo.CaBase64); err != nil {
-			return common.RespErr(c, err)
+			return nil, err
 		}
 		repoEntry.CAFile = caFilePath
 	}
 
-	r, err := repo.NewChartRepository(&repoEntry, getter.All(settings))
+	r, err := repo.NewChartRepository(&repoEntry, repoIndexGetters(&repoEntry))
 	if err != nil {
 		log.Errorf("NewChartRepository ::  %s", err.Error())
 		_ = RemoveFile(caFilePath)
-		return common.RespErr(c, err)
+		return nil, err
 	}
 
 	// set cache path
@@ -153,18 +197,38 @@ func AddRepo(c *fiber.Ctx) error {
 	if _, err := r.DownloadIndexFile(); err != nil {
 		log.Errorf("DownloadIndexFile ::  %s", err.Error())
 		_ = RemoveFile(caFilePath)
-		return common.RespErr(c, err)
+		return nil, err
 	}
 
 	f.Update(&repoEntry)
+	indexCache.invalidate(repoEntry.Name)
+	return &repoEntry, nil
+}
 
-	if err := f.WriteFile(repoFile, 0600); err != nil {
-		log.Errorf("Write Repofile ::  %s", err.Error())
-		_ = RemoveFile(caFilePath)
-		return common.RespErr(c, err)
+// sameRepoEntry reports whether the repository is configured as the new one,
+// the ca.crt files are saved under generated names so their contents are compared
+func sameRepoEntry(existing *repo.Entry, newRepo *addRepositoryElement) bool {
+	repoEntry := repo.Entry{
+		Name:     newRepo.Name,
+		URL:      newRepo.URL,
+		Username: newRepo.Username,
+		Password: newRepo.Password,
 	}
-
-	return common.RespOK(c, nil)
+	if len(existing.CAFile) > 0 || len(newRepo.CaBase64) > 0 {
+		if len(existing.CAFile) == 0 || len(newRepo.CaBase64) == 0 {
+			return false
+		}
+		existingCA, err := os.ReadFile(existing.CAFile)
+		if err != nil {
+			return false
+		}
+		newCA, err := base64.StdEncoding.DecodeString(newRepo.CaBase64)
+		if err != nil || !bytes.Equal(existingCA, newCA) {
+			return false
+		}
+		repoEntry.CAFile = existing.CAFile
+	}
+	return repoEntry == *existing
 }
 
 // ListRepos
@@ -212,6 +276,9 @@ func RemoveRepo(c *fiber.Ctx) error {
 	if !repoFile.Has(repoName) {
 		return common.RespErr(c, fmt.Errorf(common.REPO_NO_NAMED_FOUND))
 	}
+	if isHostedRepo(repoName) {
+		return common.RespErr(c, fmt.Errorf(common.HOSTED_REPO_NOT_REMOVABLE))
+	}
 	removeRepo := repoFile.Get(repoName)
 
 	if !repoFile.Remove(repoName) {
@@ -225,10 +292,15 @@ func RemoveRepo(c *fiber.Ctx) error {
 	if err := removeRepoCache(settings.RepositoryCache, repoName); err != nil {
 		return common.RespErr(c, err)
 	}
+	indexCache.invalidate(repoName)
 
 	// delete repo ca.crt
 	_ = RemoveFile(removeRepo.CAFile)
 
+	if err := removeRepoVerification(repoName); err != nil {
+		log.Errorf("Failed to remove the repo verification (name: %s, err: %s)", repoName, err)
+	}
+
 	return common.RespOK(c, nil)
 }
 
@@ -303,6 +375,170 @@ func ListRepoCharts(c *fiber.Ctx) error {
 	return common.ListRespOK(c, itemCount, resultData)
 }
 
+// ExportRepos
+// @Summary Export Repository Catalog
+// @Tags Repository
+// @Accept json
+// @Produce x-yaml
+// @Router /api/repositories/export [Get]
+func ExportRepos(c *fiber.Ctx) error {
+	credentials, err := strconv.ParseBool(c.Query("credentials", "0"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	// the credentials of every repository are only exported to SUPER_ADMIN
+	if credentials && common.UserClaims(c)["userType"] != common.AUTH_SUPER_ADMIN {
+		return common.RespErr(c, fmt.Errorf(common.REPO_EXPORT_CREDENTIALS_NOT_ALLOWED))
+	}
+
+	repositories, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		log.Errorf("ExportRepos:: faild load file :: %v", err)
+		return common.RespErr(c, fmt.Errorf(common.REPO_FAILED_LOADING_FILE))
+	}
+
+	catalog := repositoryCatalog{
+		APIVersion:   repoCatalogAPIVersion,
+		Generated:    time.Now(),
+		Repositories: make([]*addRepositoryElement, 0, len(repositories.Repositories)),
+	}
+	for _, re := range repositories.Repositories {
+		element := &addRepositoryElement{Name: re.Name, URL: re.URL}
+		if credentials {
+			element.Username = re.Username
+			element.Password = re.Password
+			if len(re.CAFile) > 0 {
+				ca, err := os.ReadFile(re.CAFile)
+				if err != nil {
+					log.Errorf("ExportRepos:: failed read ca file (name: %s, path: %s) :: %v", re.Name, re.CAFile, err)
+					return common.RespErr(c, fmt.Errorf(common.REPO_CA_FAILED_READ))
+				}
+				element.CaBase64 = base64.StdEncoding.EncodeToString(ca)
+			}
+		}
+		catalog.Repositories = append(catalog.Repositories, element)
+	}
+
+	out, err := yaml.Marshal(catalog)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	c.Attachment("repositories.yaml")
+	c.Set(fiber.HeaderContentType, "application/x-yaml")
+	return c.Send(out)
+}
+
+// ImportRepos
+// @Summary Import Repository Catalog
+// @Tags Repository
+// @Accept x-yaml
+// @Produce json
+// @Router /api/repositories/import [Post]
+func ImportRepos(c *fiber.Ctx) error {
+	mode := c.Query("mode", repoImportModeMerge)
+	if mode != repoImportModeMerge && mode != repoImportModeReplace {
+		return common.RespErr(c, fmt.Errorf(common.REPO_IMPORT_MODE_INVALID))
+	}
+
+	catalog := new(repositoryCatalog)
+	if err := yaml.Unmarshal(c.Body(), catalog); err != nil || len(catalog.Repositories) == 0 {
+		return common.RespErr(c, fmt.Errorf(common.REPO_IMPORT_FILE_INVALID))
+	}
+
+	repoFile := settings.RepositoryConfig
+	err := os.MkdirAll(filepath.Dir(repoFile), os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return common.RespErr(c, err)
+	}
+	if err := syncRepoLock(repoFile); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	f, err := repo.LoadFile(repoFile)
+	if err != nil {
+		log.Errorf("ImportRepos:: faild load file :: %v", err)
+		return common.RespErr(c, fmt.Errorf(common.REPO_FAILED_LOADING_FILE))
+	}
+
+	// merge adds to the current repositories, replace starts from an empty repositories file
+	next := f
+	if mode == repoImportModeReplace {
+		next = repo.NewFile()
+		if hostedRepoEnabled() && f.Has(config.Env.HostedRepoName) {
+			next.Add(f.Get(config.Env.HostedRepoName))
+		}
+	}
+
+	log.Infof("Import repos :: mode: %s, count: %d", mode, len(catalog.Repositories))
+	results := make([]repoImportResult, 0, len(catalog.Repositories))
+	var caFiles, replacedCAFiles []string
+	for _, newRepo := range catalog.Repositories {
+		result := repoImportResult{
+			Name:          newRepo.Name,
+			URL:           newRepo.URL,
+			ResultCode:    common.RESULT_STATUS_SUCCESS,
+			ResultMessage: common.Localize(c, common.OK),
+		}
+
+		// merge updates the repositories configured differently
+		previousCAFile := ""
+		if next.Has(newRepo.Name) {
+			previousCAFile = next.Get(newRepo.Name).CAFile
+		}
+		repoEntry, err := addRepoEntry(next, newRepo, mode == repoImportModeMerge)
+		if err != nil && (err.Error() == common.REPO_SAME_CONF_ALREADY_EXISTS || err.Error() == common.REPO_NAME_RESERVED) {
+			// a repository configured the same way and the hosted repository are left as they are
+			result.ResultCode = common.RESULT_STATUS_SKIPPED
+			result.ResultMessage = common.Localize(c, err.Error())
+		} else if err != nil {
+			result.ResultCode = common.RESULT_STATUS_FAIL
+			result.ResultMessage = common.Localize(c, err.Error())
+			// keep the current configuration of a repository that failed to be replaced
+			if mode == repoImportModeReplace && f.Has(newRepo.Name) && !next.Has(newRepo.Name) {
+				next.Add(f.Get(newRepo.Name))
+			}
+		} else {
+			if len(repoEntry.CAFile) > 0 {
+				caFiles = append(caFiles, repoEntry.CAFile)
+			}
+			if len(previousCAFile) > 0 && previousCAFile != repoEntry.CAFile {
+				replacedCAFiles = append(replacedCAFiles, previousCAFile)
+			}
+		}
+		results = append(results, result)
+	}
+
+	if err := next.WriteFile(repoFile, 0600); err != nil {
+		log.Errorf("Write Repofile ::  %s", err.Error())
+		for _, caFile := range caFiles {
+			_ = RemoveFile(caFile)
+		}
+		return common.RespErr(c, err)
+	}
+	for _, caFile := range replacedCAFiles {
+		_ = RemoveFile(caFile)
+	}
+
+	if mode == repoImportModeReplace {
+		// clean up the cache and ca.crt of replaced or removed repositories
+		for _, re := range f.Repositories {
+			if !next.Has(re.Name) {
+				log.Infof("Remove repo :: name: %s, url: %s", re.Name, re.URL)
+				if err := removeRepoCache(settings.RepositoryCache, re.Name); err != nil {
+					log.Errorf("Failed to remove the repo cache (name: %s, err: %s)", re.Name, err)
+				}
+				indexCache.invalidate(re.Name)
+			}
+			if len(re.CAFile) > 0 && (!next.Has(re.Name) || next.Get(re.Name).CAFile != re.CAFile) {
+				_ = RemoveFile(re.CAFile)
+			}
+		}
+	}
+
+	return common.RespOK(c, results)
+}
+
 func syncRepoLock(repoFile string) error {
 	repoFileExt := filepath.Ext(repoFile)
 	var lockPath string
@@ -326,11 +562,20 @@ func syncRepoLock(repoFile string) error {
 }
 
 func updateChart(repoEntry *repo.Entry) error {
+	// the hosted repository is indexed locally
+	if isHostedRepo(repoEntry.Name) {
+		hostedRepoMutex.Lock()
+		defer hostedRepoMutex.Unlock()
+		return regenerateHostedIndex()
+	}
+	if err := checkRepoURLPolicy(repoEntry.URL); err != nil {
+		return err
+	}
 	if err := getRepoConnectionStatus(repoEntry.URL); err != nil {
 		return err
 	}
 
-	chartRepository, err := repo.NewChartRepository(repoEntry, getter.All(settings))
+	chartRepository, err := repo.NewChartRepository(repoEntry, repoIndexGetters(repoEntry))
 	if err != nil {
 		return err
 	}
@@ -339,9 +584,10 @@ func updateChart(repoEntry *repo.Entry) error {
 	if settings.RepositoryCache != "" {
 		chartRepository.CachePath = settings.RepositoryCache
 	}
-	if _, err = chartRepository.DownloadIndexFile(); err != nil {
+	if _, err := chartRepository.DownloadIndexFile(); err != nil {
 		return err
 	}
+	indexCache.invalidate(repoEntry.Name)
 
 	return nil
 }
@@ -386,16 +632,17 @@ func saveRepoCaFile(caFilePath string, base64CA string) error {
 }
 
 func getRepoConnectionStatus(url string) error {
-	// default 5sec
-	client := http.Client{
-		Timeout: 5 * time.Second,
-	}
+	// default 5sec, blocked addresses are refused
+	client := newRepoHttpClient()
 	resp, err := client.Get(url)
 	if err != nil {
 		if os.IsTimeout(err) {
 			// A timeout error occurred
 			return fmt.Errorf(common.REPO_CONNECT_TIMEOUT)
 		}
+		if errors.Is(err, errBlockedAddress) {
+			return errBlockedAddress
+		}
 	}
 
 	defer func() {
@@ -432,6 +679,7 @@ func ClearRepoCache(c *fiber.Ctx) error {
 	if err != nil {
 		return common.RespErr(c, err)
 	}
+	indexCache.invalidate("")
 
 	// Update repository configurations
 	repoFailList := UpdateRepoAll(repoFile)
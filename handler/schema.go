@@ -0,0 +1,378 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"github.com/xeipuuv/gojsonschema"
+	"go-api/common"
+	"gopkg.in/yaml.v3"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+type chartValuesSchema struct {
+	Generated bool        `json:"generated"`
+	Schema    interface{} `json:"schema"`
+}
+
+type valuesElement struct {
+	Values string `json:"values"`
+}
+
+type valuesValidation struct {
+	Valid       bool                    `json:"valid"`
+	Errors      []valuesValidationError `json:"errors"`
+	UnknownKeys []string                `json:"unknown_keys"`
+}
+
+type valuesValidationError struct {
+	Path    string `json:"path"`
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// valuesInvalidError is returned by install and upgrade when the values do not match the chart schema
+type valuesInvalidError struct {
+	validation *valuesValidation
+}
+
+func (e *valuesInvalidError) Error() string {
+	return common.CHART_VALUES_INVALID
+}
+
+// GetChartValuesSchema
+// @Summary Get Chart Values Schema
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/schema [Get]
+func GetChartValuesSchema(c *fiber.Ctx) error {
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	valuesSchema, err := getChartValuesSchema(chrt)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, valuesSchema)
+}
+
+// ValidateChartValues
+// @Summary Validate Chart Values
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/validate [Post]
+func ValidateChartValues(c *fiber.Ctx) error {
+	element := new(valuesElement)
+	if err := c.BodyParser(element); err != nil {
+		return common.RespErr(c, err)
+	}
+	vals, err := mergeValues(element.Values)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	validation, err := validateChartValues(chrt, vals)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+	return common.RespOK(c, validation)
+}
+
+// checkChartValues rejects values that do not match the chart schema before install or upgrade
+func checkChartValues(chrt *chart.Chart, vals map[string]interface{}) error {
+	validation, err := validateChartValues(chrt, vals)
+	if err != nil {
+		return err
+	}
+	if !validation.Valid {
+		log.Errorf("chart values invalid:: chart: %s, errors: %+v", chrt.Name(), validation.Errors)
+		return &valuesInvalidError{validation: validation}
+	}
+	return nil
+}
+
+// respValuesErr responds with the field level errors when the values do not match the chart schema
+func respValuesErr(c *fiber.Ctx, err error) error {
+	var invalid *valuesInvalidError
+	if errors.As(err, &invalid) {
+		return common.RespErrItems(c, err, invalid.validation)
+	}
+	return common.RespErr(c, err)
+}
+
+// validateChartValues validates the user values, coalesced with the chart defaults,
+// against the schema of the chart and its subcharts and reports keys unknown to the chart defaults
+func validateChartValues(chrt *chart.Chart, vals map[string]interface{}) (*valuesValidation, error) {
+	validation := &valuesValidation{
+		Errors:      make([]valuesValidationError, 0),
+		UnknownKeys: make([]string, 0),
+	}
+
+	coalesced, err := chartutil.CoalesceValues(chrt, vals)
+	if err != nil {
+		return nil, fmt.Errorf(common.FAILED_TO_PARSE_VALUES)
+	}
+	if err := validateSchemaRecursive(chrt, coalesced, "$", validation); err != nil {
+		return nil, err
+	}
+	findUnknownKeys(chrt, vals, chrt.Values, "$", validation)
+	sort.Strings(validation.UnknownKeys)
+
+	validation.Valid = len(validation.Errors) == 0
+	return validation, nil
+}
+
+func validateSchemaRecursive(chrt *chart.Chart, vals map[string]interface{}, path string, validation *valuesValidation) error {
+	if len(chrt.Schema) > 0 {
+		valuesJSON, err := json.Marshal(vals)
+		if err != nil {
+			return fmt.Errorf(common.FAILED_TO_PARSE_VALUES)
+		}
+		result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(chrt.Schema), gojsonschema.NewBytesLoader(valuesJSON))
+		if err != nil {
+			log.Errorf("validateSchemaRecursive:: chart: %s :: %v", chrt.Name(), err)
+			return fmt.Errorf(common.CHART_SCHEMA_INVALID)
+		}
+		for _, re := range result.Errors() {
+			fields := strings.Split(re.Context().String("\x00"), "\x00")[1:]
+			if property, ok := re.Details()["property"].(string); ok && re.Type() == "required" {
+				fields = append(fields, property)
+			}
+			validation.Errors = append(validation.Errors, valuesValidationError{
+				Path:    jsonPath(path, fields...),
+				Type:    re.Type(),
+				Message: re.Description(),
+			})
+		}
+	}
+
+	for key, sub := range subchartsByKey(chrt, vals, true) {
+		subVals, ok := vals[key].(map[string]interface{})
+		if !ok {
+			subVals = map[string]interface{}{}
+		}
+		if err := validateSchemaRecursive(sub, subVals, jsonPath(path, key), validation); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// subchartsByKey returns the subcharts by their values key (alias or name),
+// subcharts disabled by their condition are left out when enabledOnly is set
+func subchartsByKey(chrt *chart.Chart, vals map[string]interface{}, enabledOnly bool) map[string]*chart.Chart {
+	subcharts := map[string]*chart.Chart{}
+	if chrt == nil {
+		return subcharts
+	}
+	for _, sub := range chrt.Dependencies() {
+		key := sub.Name()
+		condition := ""
+		for _, dep := range chrt.Metadata.Dependencies {
+			if dep.Name == sub.Name() {
+				if dep.Alias != "" {
+					key = dep.Alias
+				}
+				condition = dep.Condition
+				break
+			}
+		}
+		if enabledOnly && !conditionEnabled(condition, vals) {
+			continue
+		}
+		subcharts[key] = sub
+	}
+	return subcharts
+}
+
+// conditionEnabled evaluates a dependency condition, the first condition path found in the values wins
+func conditionEnabled(condition string, vals map[string]interface{}) bool {
+	for _, path := range strings.Split(condition, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		if value, err := chartutil.Values(vals).PathValue(path); err == nil {
+			if enabled, ok := value.(bool); ok {
+				return enabled
+			}
+		}
+	}
+	return true
+}
+
+// findUnknownKeys reports user value keys that are not in the chart defaults,
+// keys under empty default maps (e.g. resources: {}) are free-form and not reported
+func findUnknownKeys(chrt *chart.Chart, vals map[string]interface{}, defaults map[string]interface{}, path string, validation *valuesValidation) {
+	subcharts := subchartsByKey(chrt, vals, false)
+	for key, value := range vals {
+		keyPath := jsonPath(path, key)
+		if sub, ok := subcharts[key]; ok {
+			if subVals, ok := value.(map[string]interface{}); ok {
+				findUnknownKeys(sub, subVals, sub.Values, keyPath, validation)
+			}
+			continue
+		}
+		if key == chartutil.GlobalKey {
+			continue
+		}
+
+		defaultValue, ok := defaults[key]
+		if !ok {
+			validation.UnknownKeys = append(validation.UnknownKeys, keyPath)
+			continue
+		}
+		subVals, ok := value.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if subDefaults, ok := defaultValue.(map[string]interface{}); ok && len(subDefaults) > 0 {
+			findUnknownKeys(nil, subVals, subDefaults, keyPath, validation)
+		}
+	}
+}
+
+func jsonPath(path string, fields ...string) string {
+	for _, field := range fields {
+		if _, err := strconv.Atoi(field); err == nil {
+			path += "[" + field + "]"
+		} else {
+			path += "." + field
+		}
+	}
+	return path
+}
+
+// getChartValuesSchema returns the values.schema.json of the chart,
+// or a schema inferred from its values.yaml when the chart has none
+func getChartValuesSchema(chrt *chart.Chart) (*chartValuesSchema, error) {
+	if len(chrt.Schema) > 0 {
+		var schema interface{}
+		if err := json.Unmarshal(chrt.Schema, &schema); err != nil {
+			return nil, fmt.Errorf(common.CHART_SCHEMA_INVALID)
+		}
+		return &chartValuesSchema{Generated: false, Schema: schema}, nil
+	}
+
+	var root yaml.Node
+	for _, f := range chrt.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			if err := yaml.Unmarshal(f.Data, &root); err != nil {
+				return nil, fmt.Errorf(common.FAILED_TO_PARSE_VALUES)
+			}
+			break
+		}
+	}
+
+	schema := map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+	if len(root.Content) > 0 {
+		schema = inferValuesSchema(root.Content[0])
+	}
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = chrt.Name()
+	return &chartValuesSchema{Generated: true, Schema: schema}, nil
+}
+
+// inferValuesSchema builds a json schema from a values.yaml node,
+// using the values as defaults and the key comments as descriptions
+func inferValuesSchema(node *yaml.Node) map[string]interface{} {
+	if node.Kind == yaml.AliasNode && node.Alias != nil {
+		node = node.Alias
+	}
+
+	schema := map[string]interface{}{}
+	switch node.Kind {
+	case yaml.MappingNode:
+		properties := map[string]interface{}{}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			property := inferValuesSchema(value)
+			if description := commentDescription(key.HeadComment, value.LineComment, key.LineComment); description != "" {
+				property["description"] = description
+			}
+			properties[key.Value] = property
+		}
+		schema["type"] = "object"
+		schema["properties"] = properties
+	case yaml.SequenceNode:
+		schema["type"] = "array"
+		if len(node.Content) > 0 {
+			items := inferValuesSchema(node.Content[0])
+			delete(items, "default")
+			schema["items"] = items
+		}
+		setSchemaDefault(schema, node)
+	case yaml.ScalarNode:
+		switch node.ShortTag() {
+		case "!!str":
+			schema["type"] = "string"
+		case "!!int":
+			schema["type"] = "integer"
+		case "!!float":
+			schema["type"] = "number"
+		case "!!bool":
+			schema["type"] = "boolean"
+		}
+		// null values accept any type
+		if node.ShortTag() != "!!null" {
+			setSchemaDefault(schema, node)
+		}
+	}
+	return schema
+}
+
+func setSchemaDefault(schema map[string]interface{}, node *yaml.Node) {
+	var value interface{}
+	if err := node.Decode(&value); err == nil {
+		schema["default"] = value
+	}
+}
+
+// commentDescription joins the comments of a values key into a description,
+// only the last paragraph of a head comment belongs to the key
+func commentDescription(comments ...string) string {
+	var lines []string
+	for _, comment := range comments {
+		paragraphs := strings.Split(strings.TrimSpace(comment), "\n\n")
+		for _, line := range strings.Split(paragraphs[len(paragraphs)-1], "\n") {
+			line = strings.TrimSpace(strings.TrimLeft(strings.TrimSpace(line), "#"))
+			// helm-docs style "# -- description"
+			line = strings.TrimSpace(strings.TrimPrefix(line, "--"))
+			if line != "" {
+				lines = append(lines, line)
+			}
+		}
+	}
+	return strings.Join(lines, " ")
+}
@@ -0,0 +1,204 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/log"
+	"go-api/common"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+	"sort"
+	"strings"
+)
+
+const (
+	defaultTemplateReleaseName = "release-name"
+	defaultTemplateNamespace   = "default"
+	manifestSourcePrefix       = "# Source: "
+)
+
+type templateElement struct {
+	ReleaseName string   `json:"release_name"`
+	Namespace   string   `json:"namespace"`
+	Values      string   `json:"values"`
+	KubeVersion string   `json:"kube_version"`
+	APIVersions []string `json:"api_versions"`
+	IncludeCRDs bool     `json:"include_crds"`
+}
+
+type renderedChart struct {
+	Manifests []renderedManifest `json:"manifests"`
+	Hooks     []renderedManifest `json:"hooks"`
+	Notes     string             `json:"notes"`
+}
+
+type renderedManifest struct {
+	Source     string   `json:"source"`
+	APIVersion string   `json:"api_version"`
+	Kind       string   `json:"kind"`
+	Name       string   `json:"name"`
+	Namespace  string   `json:"namespace"`
+	Events     []string `json:"events,omitempty"`
+	Manifest   string   `json:"manifest"`
+}
+
+type manifestHead struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Metadata   struct {
+		Name      string `json:"name"`
+		Namespace string `json:"namespace"`
+	} `json:"metadata"`
+}
+
+// TemplateChart
+// @Summary Template Chart
+// @Tags Repository
+// @Accept json
+// @Produce json
+// @Router /api/repositories/:repositories/charts/:charts/template [Post]
+func TemplateChart(c *fiber.Ctx) error {
+	element := new(templateElement)
+	if err := c.BodyParser(element); err != nil {
+		return common.RespErr(c, err)
+	}
+
+	opts := &action.ChartPathOptions{Version: c.Query("version")}
+	cp, _, err := locateRepoChart(opts, c.Params("repositories"), c.Params("charts"))
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	chrt, err := loader.Load(cp)
+	if err != nil {
+		return common.RespErr(c, err)
+	}
+
+	rel, err := renderChart(chrt, element)
+	if err != nil {
+		return respValuesErr(c, err)
+	}
+
+	return common.RespOK(c, renderedChart{
+		Manifests: splitRenderedManifests(rel.Manifest),
+		Hooks:     renderedHooks(rel.Hooks),
+		Notes:     rel.Info.Notes,
+	})
+}
+
+// renderChart renders the chart entirely client-side like 'helm template',
+// with the given kubernetes version and api versions as cluster capabilities
+func renderChart(chrt *chart.Chart, t *templateElement) (*release.Release, error) {
+	vals, err := mergeValues(t.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	validInstallableChart, err := isChartInstallable(chrt)
+	if !validInstallableChart {
+		return nil, err
+	}
+
+	if req := chrt.Metadata.Dependencies; req != nil {
+		// missing dependencies are resolved from the configured repositories, as for an install
+		chrt, err = resolveChartDependencies(chrt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := checkChartValues(chrt, vals); err != nil {
+		return nil, err
+	}
+
+	actionConfig := &action.Configuration{Log: log.Debugf}
+	client := action.NewInstall(actionConfig)
+	client.DryRun = true
+	client.DryRunOption = "client"
+	client.ClientOnly = true
+	client.Replace = true
+	client.ReleaseName = procTemplateDefault(t.ReleaseName, defaultTemplateReleaseName)
+	client.Namespace = procTemplateDefault(t.Namespace, defaultTemplateNamespace)
+	client.IncludeCRDs = t.IncludeCRDs
+	client.APIVersions = t.APIVersions
+
+	if t.KubeVersion != "" {
+		kubeVersion, err := chartutil.ParseKubeVersion(t.KubeVersion)
+		if err != nil {
+			return nil, fmt.Errorf(common.KUBE_VERSION_INVALID)
+		}
+		client.KubeVersion = kubeVersion
+	}
+
+	rel, err := client.Run(chrt, vals)
+	if err != nil {
+		return nil, err
+	}
+	return rel, nil
+}
+
+// splitRenderedManifests splits a rendered manifest into its resources in rendering order
+func splitRenderedManifests(manifest string) []renderedManifest {
+	split := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(split))
+	for k := range split {
+		keys = append(keys, k)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	manifests := make([]renderedManifest, 0, len(keys))
+	for _, k := range keys {
+		if m, ok := newRenderedManifest(split[k]); ok {
+			manifests = append(manifests, m)
+		}
+	}
+	return manifests
+}
+
+func renderedHooks(hooks []*release.Hook) []renderedManifest {
+	manifests := make([]renderedManifest, 0, len(hooks))
+	for _, h := range hooks {
+		m, ok := newRenderedManifest(h.Manifest)
+		if !ok {
+			continue
+		}
+		m.Source = h.Path
+		for _, e := range h.Events {
+			m.Events = append(m.Events, e.String())
+		}
+		manifests = append(manifests, m)
+	}
+	return manifests
+}
+
+func newRenderedManifest(content string) (renderedManifest, bool) {
+	content = strings.TrimSpace(content)
+	var head manifestHead
+	if err := yaml.Unmarshal([]byte(content), &head); err != nil || head.Kind == "" {
+		return renderedManifest{}, false
+	}
+
+	m := renderedManifest{
+		APIVersion: head.APIVersion,
+		Kind:       head.Kind,
+		Name:       head.Metadata.Name,
+		Namespace:  head.Metadata.Namespace,
+		Manifest:   content,
+	}
+	if line, _, _ := strings.Cut(content, "\n"); strings.HasPrefix(line, manifestSourcePrefix) {
+		m.Source = strings.TrimPrefix(line, manifestSourcePrefix)
+	}
+	return m, true
+}
+
+func procTemplateDefault(value string, defaultValue string) string {
+	if strings.TrimSpace(value) == "" {
+		return defaultValue
+	}
+	return value
+}
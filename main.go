@@ -3,6 +3,7 @@ package main
 import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/log"
+	"github.com/gofiber/fiber/v2/middleware/recover"
 	"go-api/config"
 	_ "go-api/docs"
 	"go-api/handler"
@@ -27,6 +28,8 @@ func init() {
 func main() {
 	log.Info("Hello, Helm Catalog Rest API!")
 	app := fiber.New()
+	// a panicking handler fails its own request instead of the server
+	app.Use(recover.New(recover.Config{EnableStackTrace: true}))
 	router.AccessibleRoute(app)
 	middleware.FiberMiddleware(app)
 	router.APIRoutes(app)
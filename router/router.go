@@ -21,6 +21,9 @@ func AccessibleRoute(app *fiber.App) {
 		health.Get("/liveness", handler.HealthCheck)
 		health.Get("/readiness", handler.HealthCheck)
 	}
+
+	// hosted chart repository (helm repo add)
+	app.Get("/charts/:file", handler.ServeHostedRepo)
 }
 func APIRoutes(app *fiber.App) {
 	api := app.Group("/api")
@@ -32,16 +35,60 @@ func APIRoutes(app *fiber.App) {
 		repositories.Get("", handler.ListRepos)
 		// helm repo add
 		repositories.Post("", handler.AddRepo)
+		// export repositories
+		repositories.Get("/export", handler.ExportRepos)
+		// import repositories
+		repositories.Post("/import", handler.ImportRepos)
 		// helm repo remove
 		repositories.Delete("/:repositories", handler.RemoveRepo)
 		// helm repo update
 		repositories.Put("/:repositories", handler.UpdateRepo)
 		// helm search chart list
 		repositories.Get("/:repositories/charts", handler.ListRepoCharts)
+		// chart verification settings
+		repositories.Get("/:repositories/verification", handler.GetRepoVerification)
+		repositories.Put("/:repositories/verification", handler.SetRepoVerification)
 		// helm show chart
 		repositories.Get("/:repositories/charts/:charts/info", handler.GetChartInfo)
+		// chart values schema
+		repositories.Get("/:repositories/charts/:charts/schema", handler.GetChartValuesSchema)
+		// validate chart values
+		repositories.Post("/:repositories/charts/:charts/validate", handler.ValidateChartValues)
+		// helm template
+		repositories.Post("/:repositories/charts/:charts/template", handler.TemplateChart)
+		// helm lint
+		repositories.Post("/:repositories/charts/:charts/lint", handler.LintRepoChart)
+		// helm pull
+		repositories.Get("/:repositories/charts/:charts/package", handler.GetChartPackage)
+		// chart file
+		repositories.Get("/:repositories/charts/:charts/files/*", handler.GetChartFile)
+		// chart container images
+		repositories.Post("/:repositories/charts/:charts/images", handler.GetChartImages)
+		// chart dependency tree
+		repositories.Get("/:repositories/charts/:charts/dependencies", handler.GetChartDependencies)
+		// compare chart versions
+		repositories.Get("/:repositories/charts/:charts/compare", handler.CompareChartVersions)
 		// clear cache
 		repositories.Delete("/cache/clear", handler.ClearRepoCache)
+		// search index cache stats
+		repositories.Get("/cache/stats", handler.GetIndexCacheStats)
+	}
+
+	// keyrings
+	keyrings := api.Group("/keyrings")
+	{
+		keyrings.Get("", handler.ListKeyrings)
+		keyrings.Post("", handler.AddKeyring)
+		keyrings.Delete("/:keyring", handler.RemoveKeyring)
+	}
+
+	// hosted repository
+	hosted := api.Group("/hosted/charts")
+	{
+		// upload chart package
+		hosted.Post("", handler.UploadHostedChart)
+		// delete chart version
+		hosted.Delete("/:charts/versions/:version", handler.DeleteHostedChartVersion)
 	}
 
 	// artifactHub
@@ -57,6 +104,15 @@ func APIRoutes(app *fiber.App) {
 		artifact.Get("/packages/:packageID/:version/values", handler.GetHelmPackageValues)
 	}
 
+	// releases of all the accessible clusters
+	api.Get("/releases", handler.ListReleaseInventory)
+	// last drift reports of all the accessible clusters
+	api.Get("/releases/drift", handler.ListDriftReports)
+	// outdated releases of all the accessible clusters
+	api.Get("/releases/outdated", handler.ListOutdatedReleaseInventory)
+	// outdated releases
+	api.Get("/clusters/:clusterId/namespaces/:namespace/outdated", handler.ListOutdatedReleases).Name(common.LIST_OUTDATED_RELEASES)
+
 	// releases
 	releases := api.Group("/clusters/:clusterId/namespaces/:namespace/releases")
 	{
@@ -76,8 +132,25 @@ func APIRoutes(app *fiber.App) {
 		releases.Get("/:release/histories", handler.GetReleaseHistories)
 		// helm release resources status
 		releases.Get("/:release/resources", handler.GetReleaseResources)
+		// helm release container images
+		releases.Get("/:release/images", handler.GetReleaseImages)
+		// release kubernetes events
+		releases.Get("/:release/events", handler.GetReleaseEvents)
+		// release pod logs
+		releases.Get("/:release/logs", handler.GetReleaseLogs)
+		// helm release hooks
+		releases.Get("/:release/hooks", handler.GetReleaseHooks)
+		// release drift detection
+		releases.Get("/:release/drift", handler.GetReleaseDrift)
+		// helm test
+		releases.Post("/:release/tests", handler.RunReleaseTests)
 	}
 
+	// chart catalog search
+	api.Get("/charts", handler.SearchCharts)
+	// helm lint (uploaded chart)
+	api.Post("/charts/lint", handler.LintUploadedChart)
+
 	charts := api.Group("/charts/:charts")
 	{ // helm search charts (all versions)
 		charts.Get("/versions", handler.GetChartVersions)